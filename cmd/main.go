@@ -1,14 +1,22 @@
 package main
 
 import (
+	"eigen-operator-cli/pkg/network"
 	registration "eigen-operator-cli/pkg/registration"
+	"eigen-operator-cli/pkg/rpcauth"
+	"eigen-operator-cli/pkg/tx"
 	"fmt"
+	"log/slog"
+	"math/big"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
 	"github.com/primev/mev-commit/x/util"
 	"github.com/urfave/cli/v2"
 	"github.com/urfave/cli/v2/altsrc"
@@ -16,6 +24,12 @@ import (
 )
 
 var (
+	optionConfig = &cli.StringFlag{
+		Name:    "config",
+		Usage:   "Path to a YAML file setting any non-required option of the command",
+		EnvVars: []string{"CONFIG"},
+	}
+
 	optionOperatorConfig = altsrc.NewStringFlag(&cli.StringFlag{
 		Name:     "operator-config",
 		Usage:    "Path to operator.yml config file",
@@ -23,20 +37,464 @@ var (
 		Required: true,
 	})
 
+	optionRPCURLs = altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+		Name: "rpc-urls",
+		Usage: "RPC endpoints to use instead of the operator config's eth_rpc_url. Reads fail over " +
+			"from one to the next and transactions are sent to all of them",
+		EnvVars: []string{"RPC_URLS"},
+	})
+
+	optionRPCTimeout = altsrc.NewDurationFlag(&cli.DurationFlag{
+		Name:    "rpc-timeout",
+		Usage:   "How long a call to an RPC endpoint may take before failing over to the next one",
+		EnvVars: []string{"RPC_TIMEOUT"},
+		Value:   10 * time.Second,
+	})
+
+	optionRPCQuorum = altsrc.NewIntFlag(&cli.IntFlag{
+		Name: "rpc-quorum",
+		Usage: "Number of RPC endpoints that must agree on registration info, deregistration periods " +
+			"and block numbers before a command acts on them",
+		EnvVars: []string{"RPC_QUORUM"},
+		Value:   1,
+		Action: func(_ *cli.Context, i int) error {
+			if i < 1 {
+				return fmt.Errorf("invalid value: -rpc-quorum=%d, must be at least 1", i)
+			}
+			return nil
+		},
+	})
+
+	optionRPCHeadersFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "rpc-headers-file",
+		Usage: "Path to a file of HTTP headers sent to every RPC endpoint, one 'Name: value' per line. " +
+			"If unset, they are read from $" + rpcauth.EnvHeaders,
+		EnvVars: []string{"RPC_HEADERS_FILE"},
+	})
+
+	optionRPCJWTSecretFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "rpc-jwt-secret-file",
+		Usage: "Path to a file holding the hex encoded 32 byte secret RPC requests are authenticated with " +
+			"as HS256 JWTs. If unset, it is read from $" + rpcauth.EnvJWTSecret,
+		EnvVars: []string{"RPC_JWT_SECRET_FILE"},
+	})
+
+	optionRPCBasicAuthFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "rpc-basic-auth-file",
+		Usage: "Path to a file holding the 'user:password' RPC requests are authenticated with. " +
+			"If unset, it is read from $" + rpcauth.EnvBasicAuth,
+		EnvVars: []string{"RPC_BASIC_AUTH_FILE"},
+	})
+
+	optionRPCTLSCertFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "rpc-tls-cert-file",
+		Usage:   "Path to a PEM encoded TLS client certificate presented to RPC endpoints",
+		EnvVars: []string{"RPC_TLS_CERT_FILE"},
+	})
+
+	optionRPCTLSKeyFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "rpc-tls-key-file",
+		Usage:   "Path to the PEM encoded key of --rpc-tls-cert-file",
+		EnvVars: []string{"RPC_TLS_KEY_FILE"},
+	})
+
+	optionRPCTLSCAFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "rpc-tls-ca-file",
+		Usage:   "Path to PEM encoded CA certificates trusted for RPC endpoints instead of the system ones",
+		EnvVars: []string{"RPC_TLS_CA_FILE"},
+	})
+
+	optionNetwork = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "network",
+		Usage: "Network whose mev-commit AVS, DelegationManager and AVSDirectory addresses to use, " +
+			"options are '" + strings.Join(network.Names(), "', '") + "'",
+		EnvVars: []string{"NETWORK"},
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains(network.Names(), s) {
+				return fmt.Errorf("invalid value: -network=%q", s)
+			}
+			return nil
+		},
+	})
+
 	optionAVSAddress = altsrc.NewStringFlag(&cli.StringFlag{
-		Name:     "avs-address",
-		Usage:    "Address of the mev-commit AVS contract",
-		EnvVars:  []string{"AVS_ADDRESS"},
+		Name:    "avs-address",
+		Usage:   "Address of the mev-commit AVS contract, required unless set by -network",
+		EnvVars: []string{"AVS_ADDRESS"},
+	})
+
+	optionDelegationManagerAddress = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "delegation-manager-address",
+		Usage:   "Address of the EigenLayer DelegationManager, overrides -network and the operator config",
+		EnvVars: []string{"DELEGATION_MANAGER_ADDRESS"},
+	})
+
+	optionAVSDirectoryAddress = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "avs-directory-address",
+		Usage:   "Expected address of the EigenLayer AVSDirectory used by the AVS, overrides -network and the operator config",
+		EnvVars: []string{"AVS_DIRECTORY_ADDRESS"},
+	})
+
+	optionSubmissionMode = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "submission-mode",
+		Usage: "How to submit txs, options are 'wait' to send once and wait for inclusion, 'boost' to " +
+			"resend with boosted fees until included, 'fire-and-forget' to send once and exit, or " +
+			"'private' to send through -private-rpc-url, resending with boosted fees until included",
+		EnvVars: []string{"SUBMISSION_MODE"},
+		Value:   string(registration.SubmissionModeBoost),
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains(registration.SubmissionModes, registration.SubmissionMode(s)) {
+				return fmt.Errorf("invalid value: -submission-mode=%q", s)
+			}
+			return nil
+		},
+	})
+
+	optionPrivateRPCURL = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "private-rpc-url",
+		Usage:   "RPC endpoint of a private relay that keeps txs out of the public mempool, used by -submission-mode=private",
+		EnvVars: []string{"PRIVATE_RPC_URL"},
+	})
+
+	optionPrivateRPCMethod = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "private-rpc-method",
+		Usage: "JSON-RPC method txs are sent to -private-rpc-url with, options are 'eth_sendRawTransaction', " +
+			"'eth_sendPrivateTransaction' or 'eth_sendBundle'",
+		EnvVars: []string{"PRIVATE_RPC_METHOD"},
+		Value:   string(tx.RelayMethodRawTransaction),
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains(tx.RelayMethods, tx.RelayMethod(s)) {
+				return fmt.Errorf("invalid value: -private-rpc-method=%q", s)
+			}
+			return nil
+		},
+	})
+
+	optionPrivateRPCSigningKeyFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "private-rpc-signing-key-file",
+		Usage: "Path to a file holding a hex encoded private key requests to -private-rpc-url are signed with " +
+			"in the X-Flashbots-Signature header, as Flashbots requires for 'eth_sendPrivateTransaction' and 'eth_sendBundle'",
+		EnvVars: []string{"PRIVATE_RPC_SIGNING_KEY_FILE"},
+	})
+
+	optionPrivateTargetBlocks = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "private-target-blocks",
+		Usage:   "Number of blocks a tx sent to -private-rpc-url targets before it is boosted and sent again",
+		EnvVars: []string{"PRIVATE_TARGET_BLOCKS"},
+		Value:   25,
+		Action: func(_ *cli.Context, u uint64) error {
+			if u == 0 {
+				return fmt.Errorf("invalid value: -private-target-blocks=%d, must be at least 1", u)
+			}
+			return nil
+		},
+	})
+
+	optionDryRun = altsrc.NewBoolFlag(&cli.BoolFlag{
+		Name:    "dry-run",
+		Usage:   "Build, sign and simulate the transaction without broadcasting it",
+		EnvVars: []string{"DRY_RUN"},
+	})
+
+	optionForce = altsrc.NewBoolFlag(&cli.BoolFlag{
+		Name:    "force",
+		Usage:   "Proceed even if the AVS, AVSDirectory and DelegationManager contracts don't check out",
+		EnvVars: []string{"FORCE"},
+	})
+
+	optionGasLimitMultiplier = altsrc.NewFloat64Flag(&cli.Float64Flag{
+		Name:    "gas-limit-multiplier",
+		Usage:   "Multiplier applied to the estimated gas limit of each transaction as a safety margin",
+		EnvVars: []string{"GAS_LIMIT_MULTIPLIER"},
+		Value:   1.2,
+		Action: func(_ *cli.Context, f float64) error {
+			if f < 1 {
+				return fmt.Errorf("invalid value: -gas-limit-multiplier=%v, must be at least 1", f)
+			}
+			return nil
+		},
+	})
+
+	optionMaxGasLimit = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "max-gas-limit",
+		Usage:   "Absolute cap on the gas limit of each transaction, 0 for no cap",
+		EnvVars: []string{"MAX_GAS_LIMIT"},
+	})
+
+	optionGasLimit = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name: "gas-limit",
+		Usage: "Gas limit of the prepared transaction instead of its estimate, " +
+			"required for registrations, which can't be estimated before they are signed",
+		EnvVars: []string{"GAS_LIMIT"},
+	})
+
+	optionGasStrategy = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "gas-strategy",
+		Usage:   "How to price transactions, options are 'node', 'fee-history' or 'fixed'",
+		EnvVars: []string{"GAS_STRATEGY"},
+		Value:   "node",
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains([]string{"node", "fee-history", "fixed"}, s) {
+				return fmt.Errorf("invalid value: -gas-strategy=%q", s)
+			}
+			return nil
+		},
+	})
+
+	optionAccessList = altsrc.NewBoolFlag(&cli.BoolFlag{
+		Name: "access-list",
+		Usage: "On chains without EIP-1559, send access-list (type 1) transactions " +
+			"with the access list the node creates for them instead of legacy ones",
+		EnvVars: []string{"ACCESS_LIST"},
+	})
+
+	optionFeeHistoryBlocks = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "fee-history-blocks",
+		Usage:   "Number of recent blocks the fee-history gas strategy looks at",
+		EnvVars: []string{"FEE_HISTORY_BLOCKS"},
+		Value:   20,
+	})
+
+	optionFeeHistoryPercentile = altsrc.NewFloat64Flag(&cli.Float64Flag{
+		Name:    "fee-history-percentile",
+		Usage:   "Percentile of priority fees paid per block the fee-history gas strategy tips at",
+		EnvVars: []string{"FEE_HISTORY_PERCENTILE"},
+		Value:   50,
+		Action: func(_ *cli.Context, f float64) error {
+			if f < 0 || f > 100 {
+				return fmt.Errorf("invalid value: -fee-history-percentile=%v, must be between 0 and 100", f)
+			}
+			return nil
+		},
+	})
+
+	optionGasTipCap = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "gas-tip-cap",
+		Usage:   "Gas tip cap in wei used by the fixed gas strategy",
+		EnvVars: []string{"GAS_TIP_CAP"},
+	})
+
+	optionGasFeeCap = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "gas-fee-cap",
+		Usage:   "Gas fee cap in wei used by the fixed gas strategy",
+		EnvVars: []string{"GAS_FEE_CAP"},
+	})
+
+	optionMaxGasFeeCap = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "max-gas-fee-cap",
+		Usage:   "Cap in wei on the gas fee cap of any transaction, including boosted ones, 0 for no cap",
+		EnvVars: []string{"MAX_GAS_FEE_CAP"},
+	})
+
+	optionMaxGasTipCap = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "max-gas-tip-cap",
+		Usage:   "Cap in wei on the gas tip cap of any transaction, including boosted ones, 0 for no cap",
+		EnvVars: []string{"MAX_GAS_TIP_CAP"},
+	})
+
+	optionMaxTotalSpend = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "max-total-spend",
+		Usage:   "Maximum ETH spent on gas by all transactions of the command, e.g. '0.05', empty for no limit",
+		EnvVars: []string{"MAX_TOTAL_SPEND"},
+	})
+
+	optionFeeCeilingPolicy = altsrc.NewStringFlag(&cli.StringFlag{
+		Name: "fee-ceiling-policy",
+		Usage: "What to do when boosting would exceed a fee ceiling, options are 'stop' to report the " +
+			"pending tx and exit, or 'wait' to keep waiting for it at the ceiling",
+		EnvVars: []string{"FEE_CEILING_POLICY"},
+		Value:   string(tx.CeilingPolicyStop),
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains([]string{string(tx.CeilingPolicyStop), string(tx.CeilingPolicyWait)}, s) {
+				return fmt.Errorf("invalid value: -fee-ceiling-policy=%q", s)
+			}
+			return nil
+		},
+	})
+
+	optionRetryAttempts = altsrc.NewIntFlag(&cli.IntFlag{
+		Name:    "retry-attempts",
+		Usage:   "Number of times a boosted transaction is submitted, including the first",
+		EnvVars: []string{"RETRY_ATTEMPTS"},
+		Value:   10,
+		Action: func(_ *cli.Context, i int) error {
+			if i < 1 {
+				return fmt.Errorf("invalid value: -retry-attempts=%d, must be at least 1", i)
+			}
+			return nil
+		},
+	})
+
+	optionRetryWait = altsrc.NewDurationFlag(&cli.DurationFlag{
+		Name:    "retry-wait",
+		Usage:   "How long the first attempt waits for inclusion before boosting",
+		EnvVars: []string{"RETRY_WAIT"},
+		Value:   60 * time.Second,
+	})
+
+	optionRetryWaitBlocks = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "retry-wait-blocks",
+		Usage:   "How many blocks the first attempt waits for inclusion before boosting, overrides -retry-wait if set",
+		EnvVars: []string{"RETRY_WAIT_BLOCKS"},
+	})
+
+	optionRetryMaxInterval = altsrc.NewDurationFlag(&cli.DurationFlag{
+		Name:    "retry-max-interval",
+		Usage:   "Cap on how long any attempt waits for inclusion as the wait grows with -retry-backoff, 0 for no cap",
+		EnvVars: []string{"RETRY_MAX_INTERVAL"},
+		Action: func(_ *cli.Context, d time.Duration) error {
+			if d < 0 {
+				return fmt.Errorf("invalid value: -retry-max-interval=%s, must not be negative", d)
+			}
+			return nil
+		},
+	})
+
+	optionRetryMaxIntervalBlocks = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "retry-max-interval-blocks",
+		Usage:   "Cap on how many blocks any attempt waits for inclusion as the wait grows with -retry-backoff, 0 for no cap",
+		EnvVars: []string{"RETRY_MAX_INTERVAL_BLOCKS"},
+	})
+
+	optionSubscribeHeads = altsrc.NewBoolFlag(&cli.BoolFlag{
+		Name: "subscribe-heads",
+		Usage: "Count -retry-wait-blocks by subscribing to new block headers, which needs a WebSocket or IPC " +
+			"eth_rpc_url. Falls back to polling the block number if subscribing fails",
+		EnvVars: []string{"SUBSCRIBE_HEADS"},
+	})
+
+	optionBoostPercent = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "boost-percent",
+		Usage:   "Percentage by which fees are raised on every resubmission, at least 10",
+		EnvVars: []string{"BOOST_PERCENT"},
+		Value:   10,
+		Action: func(_ *cli.Context, u uint64) error {
+			if u < 10 {
+				return fmt.Errorf("invalid value: -boost-percent=%d, must be at least 10", u)
+			}
+			return nil
+		},
+	})
+
+	optionRetryBackoff = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "retry-backoff",
+		Usage:   "How the wait grows with every attempt, options are 'constant', 'linear' or 'exponential'",
+		EnvVars: []string{"RETRY_BACKOFF"},
+		Value:   string(tx.BackoffConstant),
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains([]string{
+				string(tx.BackoffConstant),
+				string(tx.BackoffLinear),
+				string(tx.BackoffExponential),
+			}, s) {
+				return fmt.Errorf("invalid value: -retry-backoff=%q", s)
+			}
+			return nil
+		},
+	})
+
+	optionConfirmations = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name: "confirmations",
+		Usage: "Number of blocks, counting its own, a transaction must be buried under before it is reported as mined. " +
+			"A transaction reorged out before then is resubmitted",
+		EnvVars: []string{"CONFIRMATIONS"},
+		Value:   1,
+	})
+
+	optionSignatureExpiry = altsrc.NewDurationFlag(&cli.DurationFlag{
+		Name:    "signature-expiry",
+		Usage:   "How long the operator's AVS registration signature stays valid",
+		EnvVars: []string{"SIGNATURE_EXPIRY"},
+		Value:   time.Hour,
+	})
+
+	optionAction = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:     "action",
+		Usage:    "Transaction to prepare, options are 'register', 'request-deregistration' or 'deregister'",
+		EnvVars:  []string{"ACTION"},
 		Required: true,
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains([]string{
+				registration.ActionRegister,
+				registration.ActionRequestDeregistration,
+				registration.ActionDeregister,
+			}, s) {
+				return fmt.Errorf("invalid value: -action=%q", s)
+			}
+			return nil
+		},
 	})
 
-	optionBoostGasParams = altsrc.NewStringFlag(&cli.StringFlag{
-		Name:     "boost-gas-params",
-		Usage:    "Whether to boost gas params to speed up tx inclusion",
-		EnvVars:  []string{"BOOST_GAS_PARAMS"},
+	optionBundle = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:     "bundle",
+		Usage:    "Path to the transaction bundle file to read",
+		EnvVars:  []string{"BUNDLE"},
 		Required: true,
 	})
 
+	optionOut = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:     "out",
+		Usage:    "Path to write the transaction bundle file to",
+		EnvVars:  []string{"OUT"},
+		Required: true,
+	})
+
+	optionValidatorPubkeys = altsrc.NewStringSliceFlag(&cli.StringSliceFlag{
+		Name:    "validator-pubkeys",
+		Usage:   "Comma-separated list of hex encoded validator BLS pubkeys",
+		EnvVars: []string{"VALIDATOR_PUBKEYS"},
+	})
+
+	optionValidatorPubkeysFile = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "validator-pubkeys-file",
+		Usage:   "Path to a file with one hex encoded validator BLS pubkey per line",
+		EnvVars: []string{"VALIDATOR_PUBKEYS_FILE"},
+	})
+
+	optionDepositData = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "deposit-data",
+		Usage:   "Path to a beacon deposit data JSON file listing the validators",
+		EnvVars: []string{"DEPOSIT_DATA"},
+	})
+
+	optionPodOwner = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "pod-owner",
+		Usage:   "Owner of the EigenPod the validators belong to, defaults to the signing account",
+		EnvVars: []string{"POD_OWNER"},
+	})
+
+	optionBatchSize = altsrc.NewIntFlag(&cli.IntFlag{
+		Name:    "batch-size",
+		Usage:   "Maximum number of validators per transaction",
+		EnvVars: []string{"BATCH_SIZE"},
+		Value:   100,
+		Action: func(_ *cli.Context, i int) error {
+			if i < 1 {
+				return fmt.Errorf("invalid value: -batch-size=%d, must be at least 1", i)
+			}
+			return nil
+		},
+	})
+
+	optionBatchGasBudget = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "batch-gas-budget",
+		Usage:   "Maximum gas limit per transaction, batches are split until they fit, 0 for no budget",
+		EnvVars: []string{"BATCH_GAS_BUDGET"},
+		Value:   10_000_000,
+	})
+
+	optionReplaceNonce = altsrc.NewUint64Flag(&cli.Uint64Flag{
+		Name:    "replace-nonce",
+		Usage:   "Deliberately replace the pending transaction at this nonce instead of refusing to proceed",
+		EnvVars: []string{"REPLACE_NONCE"},
+	})
+
+	optionFillNonceGaps = altsrc.NewBoolFlag(&cli.BoolFlag{
+		Name:    "fill-nonce-gaps",
+		Usage:   "Reuse nonces of transactions that were dropped or never sent before using new ones",
+		EnvVars: []string{"FILL_NONCE_GAPS"},
+	})
+
 	optionKeystorePassword = altsrc.NewStringFlag(&cli.StringFlag{
 		Name:     "keystore-password",
 		Usage:    "Password for the keystore",
@@ -44,6 +502,19 @@ var (
 		Required: false,
 	})
 
+	optionOutput = altsrc.NewStringFlag(&cli.StringFlag{
+		Name:    "output",
+		Usage:   "Output format of the status report, options are 'text' or 'json'",
+		EnvVars: []string{"OUTPUT"},
+		Value:   "text",
+		Action: func(_ *cli.Context, s string) error {
+			if !slices.Contains([]string{"text", "json"}, s) {
+				return fmt.Errorf("invalid value: -output=%q", s)
+			}
+			return nil
+		},
+	})
+
 	optionLogLevel = altsrc.NewStringFlag(&cli.StringFlag{
 		Name:    "log-level",
 		Usage:   "Log level, options are 'debug', 'info', 'warn', 'error'",
@@ -114,14 +585,210 @@ func readConfig(file string) (eigenclitypes.OperatorConfig, error) {
 func main() {
 	flags := []cli.Flag{
 		optionOperatorConfig,
+		optionRPCURLs,
+		optionRPCTimeout,
+		optionRPCQuorum,
+		optionRPCHeadersFile,
+		optionRPCJWTSecretFile,
+		optionRPCBasicAuthFile,
+		optionRPCTLSCertFile,
+		optionRPCTLSKeyFile,
+		optionRPCTLSCAFile,
+		optionNetwork,
 		optionAVSAddress,
-		optionBoostGasParams,
+		optionDelegationManagerAddress,
+		optionAVSDirectoryAddress,
+		optionSubmissionMode,
+		optionPrivateRPCURL,
+		optionPrivateRPCMethod,
+		optionPrivateRPCSigningKeyFile,
+		optionPrivateTargetBlocks,
+		optionDryRun,
+		optionForce,
+		optionGasLimitMultiplier,
+		optionMaxGasLimit,
+		optionGasStrategy,
+		optionAccessList,
+		optionFeeHistoryBlocks,
+		optionFeeHistoryPercentile,
+		optionGasTipCap,
+		optionGasFeeCap,
+		optionMaxGasFeeCap,
+		optionMaxGasTipCap,
+		optionMaxTotalSpend,
+		optionFeeCeilingPolicy,
+		optionRetryAttempts,
+		optionRetryWait,
+		optionRetryWaitBlocks,
+		optionSubscribeHeads,
+		optionBoostPercent,
+		optionRetryBackoff,
+		optionRetryMaxInterval,
+		optionRetryMaxIntervalBlocks,
+		optionConfirmations,
+		optionSignatureExpiry,
+		optionReplaceNonce,
+		optionFillNonceGaps,
 		optionKeystorePassword,
 		optionLogLevel,
 		optionLogFmt,
 		optionLogTags,
 	}
 
+	statusFlags := []cli.Flag{
+		optionOperatorConfig,
+		optionRPCURLs,
+		optionRPCTimeout,
+		optionRPCQuorum,
+		optionRPCHeadersFile,
+		optionRPCJWTSecretFile,
+		optionRPCBasicAuthFile,
+		optionRPCTLSCertFile,
+		optionRPCTLSKeyFile,
+		optionRPCTLSCAFile,
+		optionNetwork,
+		optionAVSAddress,
+		optionDelegationManagerAddress,
+		optionAVSDirectoryAddress,
+		optionOutput,
+		optionLogLevel,
+		optionLogFmt,
+		optionLogTags,
+	}
+
+	prepareFlags := []cli.Flag{
+		optionOperatorConfig,
+		optionRPCURLs,
+		optionRPCTimeout,
+		optionRPCQuorum,
+		optionRPCHeadersFile,
+		optionRPCJWTSecretFile,
+		optionRPCBasicAuthFile,
+		optionRPCTLSCertFile,
+		optionRPCTLSKeyFile,
+		optionRPCTLSCAFile,
+		optionNetwork,
+		optionAVSAddress,
+		optionDelegationManagerAddress,
+		optionAVSDirectoryAddress,
+		optionAction,
+		optionOut,
+		optionForce,
+		optionGasLimitMultiplier,
+		optionMaxGasLimit,
+		optionGasLimit,
+		optionGasStrategy,
+		optionAccessList,
+		optionFeeHistoryBlocks,
+		optionFeeHistoryPercentile,
+		optionGasTipCap,
+		optionGasFeeCap,
+		optionMaxGasFeeCap,
+		optionMaxGasTipCap,
+		optionMaxTotalSpend,
+		optionFeeCeilingPolicy,
+		optionSignatureExpiry,
+		optionReplaceNonce,
+		optionLogLevel,
+		optionLogFmt,
+		optionLogTags,
+	}
+
+	cancelFlags := []cli.Flag{
+		optionOperatorConfig,
+		optionRPCURLs,
+		optionRPCTimeout,
+		optionRPCQuorum,
+		optionRPCHeadersFile,
+		optionRPCJWTSecretFile,
+		optionRPCBasicAuthFile,
+		optionRPCTLSCertFile,
+		optionRPCTLSKeyFile,
+		optionRPCTLSCAFile,
+		optionSubmissionMode,
+		optionPrivateRPCURL,
+		optionPrivateRPCMethod,
+		optionPrivateRPCSigningKeyFile,
+		optionPrivateTargetBlocks,
+		optionDryRun,
+		optionGasStrategy,
+		optionAccessList,
+		optionFeeHistoryBlocks,
+		optionFeeHistoryPercentile,
+		optionGasTipCap,
+		optionGasFeeCap,
+		optionMaxGasFeeCap,
+		optionMaxGasTipCap,
+		optionMaxTotalSpend,
+		optionFeeCeilingPolicy,
+		optionRetryAttempts,
+		optionRetryWait,
+		optionRetryWaitBlocks,
+		optionSubscribeHeads,
+		optionBoostPercent,
+		optionRetryBackoff,
+		optionRetryMaxInterval,
+		optionRetryMaxIntervalBlocks,
+		optionConfirmations,
+		optionKeystorePassword,
+		optionLogLevel,
+		optionLogFmt,
+		optionLogTags,
+	}
+
+	signFlags := []cli.Flag{
+		optionOperatorConfig,
+		optionBundle,
+		optionOut,
+		optionKeystorePassword,
+		optionLogLevel,
+		optionLogFmt,
+		optionLogTags,
+	}
+
+	broadcastFlags := []cli.Flag{
+		optionOperatorConfig,
+		optionRPCURLs,
+		optionRPCTimeout,
+		optionRPCQuorum,
+		optionRPCHeadersFile,
+		optionRPCJWTSecretFile,
+		optionRPCBasicAuthFile,
+		optionRPCTLSCertFile,
+		optionRPCTLSKeyFile,
+		optionRPCTLSCAFile,
+		optionNetwork,
+		optionAVSAddress,
+		optionDelegationManagerAddress,
+		optionAVSDirectoryAddress,
+		optionBundle,
+		optionSubmissionMode,
+		optionPrivateRPCURL,
+		optionPrivateRPCMethod,
+		optionPrivateRPCSigningKeyFile,
+		optionPrivateTargetBlocks,
+		optionConfirmations,
+		optionForce,
+		optionLogLevel,
+		optionLogFmt,
+		optionLogTags,
+	}
+
+	validatorFlags := append(slices.Clone(flags),
+		optionValidatorPubkeys,
+		optionValidatorPubkeysFile,
+		optionDepositData,
+		optionPodOwner,
+		optionBatchSize,
+		optionBatchGasBudget,
+	)
+
+	lstRegisterFlags := append(slices.Clone(flags),
+		optionValidatorPubkeys,
+		optionValidatorPubkeysFile,
+		optionDepositData,
+	)
+
 	app := &cli.App{
 		Name:  "mev-commit-operator-cli",
 		Usage: "CLI for mev-commit AVS operator registration.",
@@ -144,9 +811,81 @@ func main() {
 				Flags:  flags,
 				Action: newAction((*registration.Command).DeregisterOperator),
 			},
+			{
+				Name:   "register-validators",
+				Usage:  "Register validators of an EigenPod with the AVS",
+				Flags:  validatorFlags,
+				Action: newAction((*registration.Command).RegisterValidators),
+			},
+			{
+				Name:   "request-validators-deregistration",
+				Usage:  "Request deregistration of validators",
+				Flags:  validatorFlags,
+				Action: newAction((*registration.Command).RequestValidatorsDeregistration),
+			},
+			{
+				Name:   "deregister-validators",
+				Usage:  "Deregister validators",
+				Flags:  validatorFlags,
+				Action: newAction((*registration.Command).DeregisterValidators),
+			},
+			{
+				Name:   "register-lst-restaker",
+				Usage:  "Register an LST restaker with the AVS, choosing validators to attribute its stake to",
+				Flags:  lstRegisterFlags,
+				Action: newAction((*registration.Command).RegisterLSTRestaker),
+			},
+			{
+				Name:   "request-lst-restaker-deregistration",
+				Usage:  "Request deregistration of an LST restaker",
+				Flags:  flags,
+				Action: newAction((*registration.Command).RequestLSTRestakerDeregistration),
+			},
+			{
+				Name:   "deregister-lst-restaker",
+				Usage:  "Deregister an LST restaker",
+				Flags:  flags,
+				Action: newAction((*registration.Command).DeregisterLSTRestaker),
+			},
+			{
+				Name:   "cancel-pending",
+				Usage:  "Cancel pending transactions of the operator account by replacing them with self-transfers",
+				Flags:  cancelFlags,
+				Action: newAction((*registration.Command).CancelPending),
+			},
+			{
+				Name:   "status",
+				Usage:  "Show the operator's registration status",
+				Flags:  statusFlags,
+				Action: newAction((*registration.Command).Status),
+			},
+			{
+				Name:   "prepare",
+				Usage:  "Prepare an unsigned transaction bundle for offline signing",
+				Flags:  prepareFlags,
+				Action: newAction((*registration.Command).PrepareTx),
+			},
+			{
+				Name:   "sign",
+				Usage:  "Sign a transaction bundle offline with the operator keystore",
+				Flags:  signFlags,
+				Action: newAction((*registration.Command).SignTx),
+			},
+			{
+				Name:   "broadcast",
+				Usage:  "Broadcast a signed transaction bundle and wait for it to be mined",
+				Flags:  broadcastFlags,
+				Action: newAction((*registration.Command).BroadcastTx),
+			},
 		},
 	}
 
+	// Every option but the required ones can also be set in a config file.
+	for _, cmd := range app.Commands {
+		cmd.Flags = append(slices.Clone(cmd.Flags), optionConfig)
+		cmd.Before = altsrc.InitInputSourceWithContext(cmd.Flags, altsrc.NewYamlSourceFromFlagFunc(optionConfig.Name))
+	}
+
 	if err := app.Run(os.Args); err != nil {
 		fmt.Fprintln(app.ErrWriter, err)
 	}
@@ -169,12 +908,64 @@ func newAction(action func(*registration.Command, *cli.Context) error) cli.Actio
 			logger.Error("failed to read operator config", "error", err)
 			return err
 		}
+		avsAddress, avsDirAddress, err := applyNetwork(ctx, &operConfig, logger)
+		if err != nil {
+			logger.Error("failed to configure network", "error", err)
+			return err
+		}
+		var replaceNonce *uint64
+		if ctx.IsSet(optionReplaceNonce.Name) {
+			nonce := ctx.Uint64(optionReplaceNonce.Name)
+			replaceNonce = &nonce
+		}
+		gasStrategy, err := newGasStrategy(ctx)
+		if err != nil {
+			logger.Error("failed to configure gas strategy", "error", err)
+			return err
+		}
+		feeCeiling, err := newFeeCeiling(ctx)
+		if err != nil {
+			logger.Error("failed to configure fee ceiling", "error", err)
+			return err
+		}
 		if err := action(&registration.Command{
-			Logger:              logger,
-			OperatorConfig:      &operConfig,
-			KeystorePassword:    ctx.String(optionKeystorePassword.Name),
-			MevCommitAVSAddress: ctx.String(optionAVSAddress.Name),
-			BoostGasParams:      ctx.Bool(optionBoostGasParams.Name),
+			Logger:               logger,
+			OperatorConfig:       &operConfig,
+			KeystorePassword:     ctx.String(optionKeystorePassword.Name),
+			MevCommitAVSAddress:  avsAddress,
+			AVSDirectoryAddress:  avsDirAddress,
+			SubmissionMode:       registration.SubmissionMode(ctx.String(optionSubmissionMode.Name)),
+			PrivateRPCURL:        ctx.String(optionPrivateRPCURL.Name),
+			PrivateRPCMethod:     tx.RelayMethod(ctx.String(optionPrivateRPCMethod.Name)),
+			RelaySigningKeyFile:  ctx.String(optionPrivateRPCSigningKeyFile.Name),
+			PrivateTargetBlocks:  ctx.Uint64(optionPrivateTargetBlocks.Name),
+			RPCURLs:              ctx.StringSlice(optionRPCURLs.Name),
+			RPCTimeout:           ctx.Duration(optionRPCTimeout.Name),
+			RPCQuorum:            ctx.Int(optionRPCQuorum.Name),
+			RPCAuth:              newRPCAuth(ctx),
+			DryRun:               ctx.Bool(optionDryRun.Name),
+			Force:                ctx.Bool(optionForce.Name),
+			GasLimitMultiplier:   ctx.Float64(optionGasLimitMultiplier.Name),
+			MaxGasLimit:          ctx.Uint64(optionMaxGasLimit.Name),
+			GasLimit:             ctx.Uint64(optionGasLimit.Name),
+			SignatureExpiry:      ctx.Duration(optionSignatureExpiry.Name),
+			Action:               ctx.String(optionAction.Name),
+			BundleFile:           ctx.String(optionBundle.Name),
+			OutputFile:           ctx.String(optionOut.Name),
+			ValidatorPubkeys:     ctx.StringSlice(optionValidatorPubkeys.Name),
+			ValidatorPubkeysFile: ctx.String(optionValidatorPubkeysFile.Name),
+			DepositDataFile:      ctx.String(optionDepositData.Name),
+			PodOwner:             ctx.String(optionPodOwner.Name),
+			BatchSize:            ctx.Int(optionBatchSize.Name),
+			BatchGasBudget:       ctx.Uint64(optionBatchGasBudget.Name),
+			ReplaceNonce:         replaceNonce,
+			FillNonceGaps:        ctx.Bool(optionFillNonceGaps.Name),
+			GasStrategy:          gasStrategy,
+			AccessList:           ctx.Bool(optionAccessList.Name),
+			FeeCeiling:           feeCeiling,
+			RetryPolicy:          newRetryPolicy(ctx),
+			Confirmations:        ctx.Uint64(optionConfirmations.Name),
+			OutputFormat:         ctx.String(optionOutput.Name),
 		}, ctx); err != nil {
 			logger.Error("command execution failed")
 			return err
@@ -182,3 +973,123 @@ func newAction(action func(*registration.Command, *cli.Context) error) cli.Actio
 		return nil
 	}
 }
+
+// applyNetwork resolves the AVS and AVSDirectory addresses from the network preset
+// and address flags, and sets the DelegationManager address of operConfig.
+// Address flags override the preset, which overrides the operator config.
+func applyNetwork(ctx *cli.Context, operConfig *eigenclitypes.OperatorConfig, logger *slog.Logger) (
+	avsAddress, avsDirAddress string, err error) {
+
+	if name := ctx.String(optionNetwork.Name); name != "" {
+		preset, err := network.Lookup(name, &operConfig.ChainId)
+		if err != nil {
+			return "", "", err
+		}
+		avsAddress = preset.AVSAddress.Hex()
+		if preset.AVSDirectoryAddress != (common.Address{}) {
+			avsDirAddress = preset.AVSDirectoryAddress.Hex()
+		}
+		dmAddress := preset.DelegationManagerAddress.Hex()
+		if operConfig.ELDelegationManagerAddress != "" &&
+			common.HexToAddress(operConfig.ELDelegationManagerAddress) != preset.DelegationManagerAddress {
+			logger.Warn("operator config delegation manager differs from network preset, using preset",
+				"network", name, "operatorConfig", operConfig.ELDelegationManagerAddress, "preset", dmAddress)
+		}
+		operConfig.ELDelegationManagerAddress = dmAddress
+	}
+	if ctx.IsSet(optionAVSAddress.Name) {
+		avsAddress = ctx.String(optionAVSAddress.Name)
+	}
+	if ctx.IsSet(optionAVSDirectoryAddress.Name) {
+		avsDirAddress = ctx.String(optionAVSDirectoryAddress.Name)
+	}
+	if ctx.IsSet(optionDelegationManagerAddress.Name) {
+		operConfig.ELDelegationManagerAddress = ctx.String(optionDelegationManagerAddress.Name)
+	}
+
+	for _, addr := range []string{avsAddress, avsDirAddress, operConfig.ELDelegationManagerAddress} {
+		if addr != "" && !common.IsHexAddress(addr) {
+			return "", "", fmt.Errorf("invalid address: %q", addr)
+		}
+	}
+	if avsAddress == "" && slices.Contains(ctx.Command.Flags, cli.Flag(optionAVSAddress)) {
+		return "", "", fmt.Errorf("either -%s or -%s must be set", optionAVSAddress.Name, optionNetwork.Name)
+	}
+	return avsAddress, avsDirAddress, nil
+}
+
+// newGasStrategy builds the gas pricing strategy selected by the gas flags.
+func newGasStrategy(ctx *cli.Context) (tx.GasStrategy, error) {
+	var strategy tx.GasStrategy
+	switch ctx.String(optionGasStrategy.Name) {
+	case "fee-history":
+		strategy = tx.FeeHistoryGasStrategy{
+			Blocks:     ctx.Uint64(optionFeeHistoryBlocks.Name),
+			Percentile: ctx.Float64(optionFeeHistoryPercentile.Name),
+		}
+	case "fixed":
+		if !ctx.IsSet(optionGasTipCap.Name) || !ctx.IsSet(optionGasFeeCap.Name) {
+			return nil, fmt.Errorf("gas strategy %q requires --%s and --%s",
+				"fixed", optionGasTipCap.Name, optionGasFeeCap.Name)
+		}
+		strategy = tx.FixedGasStrategy{
+			GasTipCap: new(big.Int).SetUint64(ctx.Uint64(optionGasTipCap.Name)),
+			GasFeeCap: new(big.Int).SetUint64(ctx.Uint64(optionGasFeeCap.Name)),
+		}
+	default:
+		strategy = tx.NodeGasStrategy{}
+	}
+	return strategy, nil
+}
+
+// newFeeCeiling builds the fee ceiling selected by the fee ceiling flags.
+func newFeeCeiling(ctx *cli.Context) (*tx.FeeCeiling, error) {
+	ceiling := &tx.FeeCeiling{
+		MaxGasFeeCap: optionalWei(ctx, optionMaxGasFeeCap),
+		MaxGasTipCap: optionalWei(ctx, optionMaxGasTipCap),
+		Policy:       tx.CeilingPolicy(ctx.String(optionFeeCeilingPolicy.Name)),
+	}
+	if s := ctx.String(optionMaxTotalSpend.Name); s != "" {
+		eth, ok := new(big.Rat).SetString(s)
+		if !ok || eth.Sign() < 0 {
+			return nil, fmt.Errorf("invalid value: -%s=%q", optionMaxTotalSpend.Name, s)
+		}
+		wei := eth.Mul(eth, new(big.Rat).SetInt(big.NewInt(params.Ether)))
+		ceiling.MaxTotalSpend = new(big.Int).Quo(wei.Num(), wei.Denom())
+	}
+	return ceiling, nil
+}
+
+// newRetryPolicy builds the retry schedule selected by the retry flags.
+func newRetryPolicy(ctx *cli.Context) *tx.RetryPolicy {
+	return &tx.RetryPolicy{
+		Attempts:       ctx.Int(optionRetryAttempts.Name),
+		Wait:           ctx.Duration(optionRetryWait.Name),
+		WaitBlocks:     ctx.Uint64(optionRetryWaitBlocks.Name),
+		SubscribeHeads: ctx.Bool(optionSubscribeHeads.Name),
+		BoostPercent:   ctx.Uint64(optionBoostPercent.Name),
+		Backoff:        tx.Backoff(ctx.String(optionRetryBackoff.Name)),
+		MaxWait:        ctx.Duration(optionRetryMaxInterval.Name),
+		MaxWaitBlocks:  ctx.Uint64(optionRetryMaxIntervalBlocks.Name),
+	}
+}
+
+// newRPCAuth locates the RPC credentials selected by the rpc auth flags.
+func newRPCAuth(ctx *cli.Context) rpcauth.Config {
+	return rpcauth.Config{
+		HeadersFile:   ctx.String(optionRPCHeadersFile.Name),
+		JWTSecretFile: ctx.String(optionRPCJWTSecretFile.Name),
+		BasicAuthFile: ctx.String(optionRPCBasicAuthFile.Name),
+		TLSCertFile:   ctx.String(optionRPCTLSCertFile.Name),
+		TLSKeyFile:    ctx.String(optionRPCTLSKeyFile.Name),
+		TLSCAFile:     ctx.String(optionRPCTLSCAFile.Name),
+	}
+}
+
+// optionalWei returns the value of a wei amount flag, or nil if it is 0.
+func optionalWei(ctx *cli.Context, flag *altsrc.Uint64Flag) *big.Int {
+	if v := ctx.Uint64(flag.Name); v != 0 {
+		return new(big.Int).SetUint64(v)
+	}
+	return nil
+}
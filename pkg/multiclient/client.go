@@ -0,0 +1,470 @@
+package multiclient
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/big"
+	"net"
+	"slices"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/ethclient/gethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// Client stands in for ethclient.Client wherever the CLI uses one.
+var (
+	_ bind.ContractBackend = (*Client)(nil)
+	_ tx.EthClient         = (*Client)(nil)
+	_ tx.HeadSubscriber    = (*Client)(nil)
+	_ tx.AccessListCreator = (*Client)(nil)
+)
+
+// ErrNoQuorum is returned when not enough endpoints agree on the result of a read
+// that requires a quorum.
+var ErrNoQuorum = errors.New("rpc endpoints did not reach quorum")
+
+// Client is an Ethereum client backed by several RPC endpoints. Calls go to one
+// endpoint at a time, failing over to the next one on connection and timeout
+// errors and sticking to the first that answers. Transactions are sent to every
+// endpoint. Block numbers and contract calls can require a quorum of endpoints
+// to agree, see WithQuorum.
+type Client struct {
+	endpoints []endpoint
+	timeout   time.Duration
+	quorum    int
+	logger    *slog.Logger
+	state     *state
+}
+
+// endpoint is a connected RPC endpoint and its index in the configured urls.
+type endpoint struct {
+	*ethclient.Client
+	index int
+}
+
+// state is the endpoint in use, shared by a client and its quorum views.
+type state struct {
+	mu      sync.Mutex
+	current int
+}
+
+// Dial connects to the endpoints in urls with opts, e.g. credentials. Endpoints
+// are referred to by their index in logs, and skipped if they cannot be connected
+// to. Each call to an endpoint times out after timeout, if set, before failing
+// over to the next one.
+func Dial(
+	ctx context.Context,
+	urls []string,
+	timeout time.Duration,
+	logger *slog.Logger,
+	opts ...rpc.ClientOption,
+) (*Client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no rpc endpoints configured")
+	}
+	endpoints := make([]endpoint, 0, len(urls))
+	for i, url := range urls {
+		client, err := rpc.DialOptions(ctx, url, opts...)
+		if err != nil {
+			// The url is left out, as it may contain credentials.
+			logger.Warn("failed to connect to rpc endpoint, skipping it", "endpoint", i, "error", err)
+			continue
+		}
+		endpoints = append(endpoints, endpoint{Client: ethclient.NewClient(client), index: i})
+	}
+	if len(endpoints) == 0 {
+		return nil, fmt.Errorf("failed to connect to any of %d rpc endpoints", len(urls))
+	}
+	return &Client{
+		endpoints: endpoints,
+		timeout:   timeout,
+		quorum:    1,
+		logger:    logger,
+		state:     &state{},
+	}, nil
+}
+
+// WithQuorum returns a view of the client whose BlockNumber and CallContract
+// require quorum endpoints to agree before returning a result.
+func (c *Client) WithQuorum(quorum int) (*Client, error) {
+	if quorum < 1 || quorum > len(c.endpoints) {
+		return nil, fmt.Errorf("quorum must be between 1 and the %d connected rpc endpoints: %d",
+			len(c.endpoints), quorum)
+	}
+	view := *c
+	view.quorum = quorum
+	return &view, nil
+}
+
+// isEndpointError reports whether err means the endpoint could not be reached or
+// did not answer in time, rather than answering with an error.
+func isEndpointError(err error) bool {
+	var netErr net.Error
+	var httpErr rpc.HTTPError
+	switch {
+	case errors.Is(err, context.DeadlineExceeded),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, rpc.ErrClientQuit),
+		errors.As(err, &netErr):
+		return true
+	case errors.As(err, &httpErr):
+		return httpErr.StatusCode >= 500 || httpErr.StatusCode == 429
+	}
+	return false
+}
+
+// callContext bounds a call to a single endpoint by the client's timeout.
+func (c *Client) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout == 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+// call runs fn against the endpoint in use, failing over to the following ones
+// while fn fails with an endpoint error.
+func call[T any](ctx context.Context, c *Client, fn func(context.Context, *ethclient.Client) (T, error)) (T, error) {
+	c.state.mu.Lock()
+	start := c.state.current
+	c.state.mu.Unlock()
+
+	var result T
+	var err error
+	for i := range c.endpoints {
+		index := (start + i) % len(c.endpoints)
+		callCtx, cancel := c.callContext(ctx)
+		result, err = fn(callCtx, c.endpoints[index].Client)
+		cancel()
+		if err == nil || !isEndpointError(err) || ctx.Err() != nil {
+			if index != start {
+				c.state.mu.Lock()
+				c.state.current = index
+				c.state.mu.Unlock()
+			}
+			return result, err
+		}
+		c.logger.Warn("rpc endpoint failed, failing over", "endpoint", c.endpoints[index].index, "error", err)
+	}
+	var zero T
+	return zero, fmt.Errorf("all %d rpc endpoints failed: %w", len(c.endpoints), err)
+}
+
+// callAll runs fn against every endpoint concurrently, returning its results and
+// errors by endpoint.
+func callAll[T any](ctx context.Context, c *Client, fn func(context.Context, *ethclient.Client) (T, error)) ([]T, []error) {
+	results := make([]T, len(c.endpoints))
+	errs := make([]error, len(c.endpoints))
+	var wg sync.WaitGroup
+	for i, e := range c.endpoints {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			callCtx, cancel := c.callContext(ctx)
+			defer cancel()
+			results[i], errs[i] = fn(callCtx, e.Client)
+		}()
+	}
+	wg.Wait()
+	return results, errs
+}
+
+// ChainID returns the chain ID of the endpoints. Every endpoint that answers must
+// be on the same chain, since transactions are sent to all of them.
+func (c *Client) ChainID(ctx context.Context) (*big.Int, error) {
+	chainIDs, errs := callAll(ctx, c, func(ctx context.Context, e *ethclient.Client) (*big.Int, error) {
+		return e.ChainID(ctx)
+	})
+	var chainID *big.Int
+	for i, err := range errs {
+		if err != nil {
+			c.logger.Warn("failed to get chain ID of rpc endpoint", "endpoint", c.endpoints[i].index, "error", err)
+			continue
+		}
+		if chainID == nil {
+			chainID = chainIDs[i]
+		} else if chainID.Cmp(chainIDs[i]) != 0 {
+			return nil, fmt.Errorf("rpc endpoints are on different chains: %s != %s", chainID, chainIDs[i])
+		}
+	}
+	if chainID == nil {
+		return nil, fmt.Errorf("failed to get chain ID from any rpc endpoint: %w", errors.Join(errs...))
+	}
+	return chainID, nil
+}
+
+// BlockNumber returns the most recent block number. With a quorum, it is the
+// highest block that at least quorum endpoints have reached.
+func (c *Client) BlockNumber(ctx context.Context) (uint64, error) {
+	if c.quorum <= 1 {
+		return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (uint64, error) {
+			return e.BlockNumber(ctx)
+		})
+	}
+	heads, errs := callAll(ctx, c, func(ctx context.Context, e *ethclient.Client) (uint64, error) {
+		return e.BlockNumber(ctx)
+	})
+	var reached []uint64
+	for i, err := range errs {
+		if err == nil {
+			reached = append(reached, heads[i])
+		}
+	}
+	if len(reached) < c.quorum {
+		return 0, fmt.Errorf("%w: %d of %d endpoints returned a block number, %d required: %w",
+			ErrNoQuorum, len(reached), len(c.endpoints), c.quorum, errors.Join(errs...))
+	}
+	slices.Sort(reached)
+	slices.Reverse(reached)
+	return reached[c.quorum-1], nil
+}
+
+// CallContract executes a message call. With a quorum, the call is made on every
+// endpoint at the same block, the quorum block number if none is given, and its
+// result is only returned if at least quorum endpoints agree on it.
+func (c *Client) CallContract(ctx context.Context, msg ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	if c.quorum <= 1 {
+		return call(ctx, c, func(ctx context.Context, e *ethclient.Client) ([]byte, error) {
+			return e.CallContract(ctx, msg, blockNumber)
+		})
+	}
+	if blockNumber == nil {
+		head, err := c.BlockNumber(ctx)
+		if err != nil {
+			return nil, err
+		}
+		blockNumber = new(big.Int).SetUint64(head)
+	}
+	results, errs := callAll(ctx, c, func(ctx context.Context, e *ethclient.Client) ([]byte, error) {
+		return e.CallContract(ctx, msg, blockNumber)
+	})
+
+	// Endpoints agree on a result, or on the error a call failed with, e.g. a revert.
+	votes := make(map[string]int)
+	for i, err := range errs {
+		key := "result:" + string(results[i])
+		if err != nil {
+			if isEndpointError(err) {
+				continue
+			}
+			key = "error:" + err.Error()
+		}
+		votes[key]++
+		if votes[key] == c.quorum {
+			return results[i], err
+		}
+	}
+	return nil, fmt.Errorf("%w: endpoints disagree on call to %s at block %s: %w",
+		ErrNoQuorum, msg.To, blockNumber, errors.Join(errs...))
+}
+
+// SendTransaction sends tx to every endpoint. It succeeds if any endpoint accepts
+// it, otherwise an error returned by an endpoint that answered is preferred.
+func (c *Client) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	_, errs := callAll(ctx, c, func(ctx context.Context, e *ethclient.Client) (struct{}, error) {
+		return struct{}{}, e.SendTransaction(ctx, tx)
+	})
+	if slices.Contains(errs, nil) {
+		for i, err := range errs {
+			if err != nil {
+				c.logger.Debug("rpc endpoint rejected tx",
+					"endpoint", c.endpoints[i].index, "txHash", tx.Hash().Hex(), "error", err)
+			}
+		}
+		return nil
+	}
+	for _, err := range errs {
+		if !isEndpointError(err) {
+			return err
+		}
+	}
+	return fmt.Errorf("all %d rpc endpoints failed: %w", len(c.endpoints), errs[0])
+}
+
+func (c *Client) CodeAt(ctx context.Context, account common.Address, blockNumber *big.Int) ([]byte, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) ([]byte, error) {
+		return e.CodeAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) ([]byte, error) {
+		return e.PendingCodeAt(ctx, account)
+	})
+}
+
+func (c *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*types.Header, error) {
+		return e.HeaderByNumber(ctx, number)
+	})
+}
+
+func (c *Client) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*big.Int, error) {
+		return e.BalanceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (uint64, error) {
+		return e.NonceAt(ctx, account, blockNumber)
+	})
+}
+
+func (c *Client) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (uint64, error) {
+		return e.PendingNonceAt(ctx, account)
+	})
+}
+
+func (c *Client) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*big.Int, error) {
+		return e.SuggestGasPrice(ctx)
+	})
+}
+
+func (c *Client) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*big.Int, error) {
+		return e.SuggestGasTipCap(ctx)
+	})
+}
+
+func (c *Client) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*ethereum.FeeHistory, error) {
+		return e.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+func (c *Client) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (uint64, error) {
+		return e.EstimateGas(ctx, msg)
+	})
+}
+
+// CreateAccessList returns the access list of msg at the latest block, as
+// created by eth_createAccessList.
+func (c *Client) CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (types.AccessList, error) {
+		accessList, _, vmErr, err := gethclient.New(e.Client()).CreateAccessList(ctx, msg)
+		if err != nil {
+			return nil, err
+		}
+		if vmErr != "" {
+			return nil, fmt.Errorf("execution failed: %s", vmErr)
+		}
+		return *accessList, nil
+	})
+}
+
+func (c *Client) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*types.Receipt, error) {
+		return e.TransactionReceipt(ctx, txHash)
+	})
+}
+
+func (c *Client) TransactionByHash(ctx context.Context, hash common.Hash) (*types.Transaction, bool, error) {
+	type txWithPending struct {
+		tx      *types.Transaction
+		pending bool
+	}
+	result, err := call(ctx, c, func(ctx context.Context, e *ethclient.Client) (txWithPending, error) {
+		tx, pending, err := e.TransactionByHash(ctx, hash)
+		return txWithPending{tx: tx, pending: pending}, err
+	})
+	return result.tx, result.pending, err
+}
+
+// TransactionBySenderAndNonce returns the tx from sent at nonce, as known to the
+// endpoint in use, with eth_getTransactionBySenderAndNonce or, on nodes that don't
+// serve it, from txpool_content. It returns ethereum.NotFound if the node knows
+// of no such tx.
+func (c *Client) TransactionBySenderAndNonce(
+	ctx context.Context, from common.Address, nonce uint64,
+) (*types.Transaction, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (*types.Transaction, error) {
+		var tx *types.Transaction
+		err := e.Client().CallContext(ctx, &tx, "eth_getTransactionBySenderAndNonce", from, hexutil.Uint64(nonce))
+		if err == nil {
+			if tx == nil {
+				return nil, ethereum.NotFound
+			}
+			return tx, nil
+		}
+		var content map[string]map[common.Address]map[string]*types.Transaction
+		if poolErr := e.Client().CallContext(ctx, &content, "txpool_content"); poolErr != nil {
+			return nil, errors.Join(err, poolErr)
+		}
+		for _, pool := range []string{"pending", "queued"} {
+			if tx := content[pool][from][strconv.FormatUint(nonce, 10)]; tx != nil {
+				return tx, nil
+			}
+		}
+		return nil, ethereum.NotFound
+	})
+}
+
+func (c *Client) TransactionSender(
+	ctx context.Context, tx *types.Transaction, block common.Hash, index uint,
+) (common.Address, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) (common.Address, error) {
+		return e.TransactionSender(ctx, tx, block, index)
+	})
+}
+
+func (c *Client) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	return call(ctx, c, func(ctx context.Context, e *ethclient.Client) ([]types.Log, error) {
+		return e.FilterLogs(ctx, q)
+	})
+}
+
+// SubscribeFilterLogs subscribes through the first endpoint that supports subscriptions.
+func (c *Client) SubscribeFilterLogs(
+	ctx context.Context, q ethereum.FilterQuery, ch chan<- types.Log,
+) (ethereum.Subscription, error) {
+	return subscribe(ctx, c, func(ctx context.Context, e *ethclient.Client) (ethereum.Subscription, error) {
+		return e.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+// SubscribeNewHead subscribes through the first endpoint that supports subscriptions.
+func (c *Client) SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	return subscribe(ctx, c, func(ctx context.Context, e *ethclient.Client) (ethereum.Subscription, error) {
+		return e.SubscribeNewHead(ctx, ch)
+	})
+}
+
+// subscribe tries fn on every endpoint in order, since only WebSocket and IPC
+// endpoints support subscriptions.
+func subscribe(
+	ctx context.Context,
+	c *Client,
+	fn func(context.Context, *ethclient.Client) (ethereum.Subscription, error),
+) (ethereum.Subscription, error) {
+	var errs []error
+	for _, e := range c.endpoints {
+		sub, err := fn(ctx, e.Client)
+		if err == nil {
+			return sub, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, fmt.Errorf("no rpc endpoint supports the subscription: %w", errors.Join(errs...))
+}
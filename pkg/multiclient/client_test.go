@@ -0,0 +1,380 @@
+package multiclient_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/multiclient"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+// ethStandIn serves the eth methods used by the tests over JSON-RPC.
+type ethStandIn struct {
+	chainID    uint64
+	head       uint64
+	callResult hexutil.Bytes
+	callErr    error
+	sendErr    error
+	sent       atomic.Int32
+}
+
+func (e *ethStandIn) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(new(big.Int).SetUint64(e.chainID))
+}
+
+func (e *ethStandIn) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(e.head)
+}
+
+func (e *ethStandIn) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	return e.callResult, e.callErr
+}
+
+func (e *ethStandIn) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	e.sent.Add(1)
+	return crypto.Keccak256Hash(raw), e.sendErr
+}
+
+// senderNonceStandIn adds eth_getTransactionBySenderAndNonce, which only some
+// clients serve, to an ethStandIn.
+type senderNonceStandIn struct {
+	*ethStandIn
+	txs map[uint64]*types.Transaction
+}
+
+func (e senderNonceStandIn) GetTransactionBySenderAndNonce(
+	from common.Address, nonce hexutil.Uint64) *types.Transaction {
+	return e.txs[uint64(nonce)]
+}
+
+// txpoolStandIn serves txpool_content.
+type txpoolStandIn struct {
+	pending map[common.Address]map[string]*types.Transaction
+}
+
+func (p *txpoolStandIn) Content() map[string]map[common.Address]map[string]*types.Transaction {
+	return map[string]map[common.Address]map[string]*types.Transaction{
+		"pending": p.pending,
+		"queued":  {},
+	}
+}
+
+// endpoint is a test RPC endpoint, serving stand-in over HTTP unless it is down
+// or too slow to answer. It serves eth_getTransactionBySenderAndNonce if
+// senderNonceTxs is set, and txpool_content if txpool is.
+type endpoint struct {
+	standIn        *ethStandIn
+	senderNonceTxs map[uint64]*types.Transaction
+	txpool         *txpoolStandIn
+	down           bool
+	slow           bool
+}
+
+func dial(t *testing.T, endpoints []endpoint) *multiclient.Client {
+	var urls []string
+	for _, e := range endpoints {
+		server := rpc.NewServer()
+		var eth any = e.standIn
+		if e.senderNonceTxs != nil {
+			eth = senderNonceStandIn{ethStandIn: e.standIn, txs: e.senderNonceTxs}
+		}
+		assert.NilError(t, server.RegisterName("eth", eth))
+		if e.txpool != nil {
+			assert.NilError(t, server.RegisterName("txpool", e.txpool))
+		}
+		t.Cleanup(server.Stop)
+		var handler http.Handler = server
+		release := make(chan struct{})
+		if e.slow {
+			handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				<-release
+			})
+		}
+		httpServer := httptest.NewServer(handler)
+		if e.down {
+			httpServer.Close()
+		} else {
+			t.Cleanup(httpServer.Close)
+		}
+		// Cleanups run last in first, so slow handlers return before the server closes.
+		t.Cleanup(func() { close(release) })
+		urls = append(urls, httpServer.URL)
+	}
+	client, err := multiclient.Dial(context.Background(), urls, 100*time.Millisecond, slog.Default())
+	assert.NilError(t, err)
+	return client
+}
+
+func TestClientFailover(t *testing.T) {
+	testCases := []struct {
+		name              string
+		endpoints         []endpoint
+		expectedHead      uint64
+		errExpectedOutput string
+	}{
+		{
+			name:         "first endpoint up",
+			endpoints:    []endpoint{{standIn: &ethStandIn{head: 100}}, {standIn: &ethStandIn{head: 200}}},
+			expectedHead: 100,
+		},
+		{
+			name:         "first endpoint down",
+			endpoints:    []endpoint{{standIn: &ethStandIn{head: 100}, down: true}, {standIn: &ethStandIn{head: 200}}},
+			expectedHead: 200,
+		},
+		{
+			name:         "first endpoint times out",
+			endpoints:    []endpoint{{standIn: &ethStandIn{head: 100}, slow: true}, {standIn: &ethStandIn{head: 200}}},
+			expectedHead: 200,
+		},
+		{
+			name: "error, all endpoints down",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{}, down: true},
+				{standIn: &ethStandIn{}, slow: true},
+			},
+			errExpectedOutput: "all 2 rpc endpoints failed",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, tc.endpoints)
+			head, err := client.BlockNumber(context.Background())
+			if tc.errExpectedOutput != "" {
+				assert.ErrorContains(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedHead, head)
+		})
+	}
+}
+
+func TestClientCallNoFailoverOnAnswer(t *testing.T) {
+	client := dial(t, []endpoint{
+		{standIn: &ethStandIn{callErr: errors.New("execution reverted")}},
+		{standIn: &ethStandIn{callResult: []byte{1}}},
+	})
+	_, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+	assert.ErrorContains(t, err, "execution reverted")
+}
+
+func TestClientSendTransaction(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	signedTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+	})
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name              string
+		endpoints         []endpoint
+		expectedSent      []int32
+		errExpectedOutput string
+	}{
+		{
+			name:         "sent to every endpoint",
+			endpoints:    []endpoint{{standIn: &ethStandIn{}}, {standIn: &ethStandIn{}}},
+			expectedSent: []int32{1, 1},
+		},
+		{
+			name: "accepted by one endpoint",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{}, down: true},
+				{standIn: &ethStandIn{sendErr: errors.New("already known")}},
+				{standIn: &ethStandIn{}},
+			},
+			expectedSent: []int32{0, 1, 1},
+		},
+		{
+			name: "error, rejected by every endpoint",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{}, down: true},
+				{standIn: &ethStandIn{sendErr: errors.New("nonce too low")}},
+			},
+			expectedSent:      []int32{0, 1},
+			errExpectedOutput: "nonce too low",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, tc.endpoints)
+			err := client.SendTransaction(context.Background(), signedTx)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+			} else {
+				assert.NilError(t, err)
+			}
+			for i, e := range tc.endpoints {
+				assert.Equal(t, tc.expectedSent[i], e.standIn.sent.Load())
+			}
+		})
+	}
+}
+
+func TestClientTransactionBySenderAndNonce(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	pendingTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     5,
+		GasTipCap: big.NewInt(3),
+		GasFeeCap: big.NewInt(300),
+		Gas:       21000,
+	})
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name              string
+		endpoint          endpoint
+		errExpectedOutput string
+	}{
+		{
+			name:     "by sender and nonce",
+			endpoint: endpoint{standIn: &ethStandIn{}, senderNonceTxs: map[uint64]*types.Transaction{5: pendingTx}},
+		},
+		{
+			name: "from txpool content",
+			endpoint: endpoint{standIn: &ethStandIn{}, txpool: &txpoolStandIn{
+				pending: map[common.Address]map[string]*types.Transaction{from: {"5": pendingTx}},
+			}},
+		},
+		{
+			name:              "error, not found",
+			endpoint:          endpoint{standIn: &ethStandIn{}, senderNonceTxs: map[uint64]*types.Transaction{}},
+			errExpectedOutput: ethereum.NotFound.Error(),
+		},
+		{
+			name: "error, not in txpool",
+			endpoint: endpoint{standIn: &ethStandIn{}, txpool: &txpoolStandIn{
+				pending: map[common.Address]map[string]*types.Transaction{from: {"4": pendingTx}},
+			}},
+			errExpectedOutput: ethereum.NotFound.Error(),
+		},
+		{
+			name:              "error, lookup not supported",
+			endpoint:          endpoint{standIn: &ethStandIn{}},
+			errExpectedOutput: "the method txpool_content does not exist/is not available",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := dial(t, []endpoint{tc.endpoint})
+			tx, err := client.TransactionBySenderAndNonce(context.Background(), from, 5)
+			if tc.errExpectedOutput != "" {
+				assert.ErrorContains(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, pendingTx.Hash(), tx.Hash())
+			assert.Equal(t, pendingTx.GasFeeCap().String(), tx.GasFeeCap().String())
+		})
+	}
+}
+
+func TestClientQuorum(t *testing.T) {
+	testCases := []struct {
+		name           string
+		endpoints      []endpoint
+		quorum         int
+		expectedHead   uint64
+		expectedResult []byte
+		expectedErr    error
+	}{
+		{
+			name: "highest block reached by quorum",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{head: 102, callResult: []byte{1}}},
+				{standIn: &ethStandIn{head: 100, callResult: []byte{1}}},
+				{standIn: &ethStandIn{head: 101, callResult: []byte{2}}},
+			},
+			quorum:         2,
+			expectedHead:   101,
+			expectedResult: []byte{1},
+		},
+		{
+			name: "quorum on revert",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{head: 100, callErr: errors.New("execution reverted")}},
+				{standIn: &ethStandIn{head: 100, callErr: errors.New("execution reverted")}},
+				{standIn: &ethStandIn{head: 100, callResult: []byte{1}}},
+			},
+			quorum:       2,
+			expectedHead: 100,
+			expectedErr:  errors.New("execution reverted"),
+		},
+		{
+			name: "no quorum on call result",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{head: 100, callResult: []byte{1}}},
+				{standIn: &ethStandIn{head: 100, callResult: []byte{2}}},
+				{standIn: &ethStandIn{head: 100, callResult: []byte{3}}},
+			},
+			quorum:       2,
+			expectedHead: 100,
+			expectedErr:  multiclient.ErrNoQuorum,
+		},
+		{
+			name: "no quorum with endpoint down",
+			endpoints: []endpoint{
+				{standIn: &ethStandIn{head: 100}},
+				{standIn: &ethStandIn{head: 100}, down: true},
+			},
+			quorum:      2,
+			expectedErr: multiclient.ErrNoQuorum,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client, err := dial(t, tc.endpoints).WithQuorum(tc.quorum)
+			assert.NilError(t, err)
+
+			head, err := client.BlockNumber(context.Background())
+			if err == nil {
+				assert.Equal(t, tc.expectedHead, head)
+			}
+			result, err := client.CallContract(context.Background(), ethereum.CallMsg{}, nil)
+			switch {
+			case errors.Is(tc.expectedErr, multiclient.ErrNoQuorum):
+				assert.Assert(t, errors.Is(err, multiclient.ErrNoQuorum), "unexpected error: %v", err)
+			case tc.expectedErr != nil:
+				assert.ErrorContains(t, err, tc.expectedErr.Error())
+			default:
+				assert.NilError(t, err)
+				assert.DeepEqual(t, tc.expectedResult, result)
+			}
+		})
+	}
+}
+
+func TestClientChainID(t *testing.T) {
+	client := dial(t, []endpoint{
+		{standIn: &ethStandIn{chainID: 1}},
+		{standIn: &ethStandIn{chainID: 1}, down: true},
+		{standIn: &ethStandIn{chainID: 17000}},
+	})
+	_, err := client.ChainID(context.Background())
+	assert.Error(t, err, "rpc endpoints are on different chains: 1 != 17000")
+
+	_, err = client.WithQuorum(4)
+	assert.Assert(t, err != nil && strings.Contains(err.Error(), "quorum must be between 1 and the 3"))
+}
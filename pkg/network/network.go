@@ -0,0 +1,71 @@
+package network
+
+import (
+	"fmt"
+	"math/big"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Preset bundles the contracts an operator interacts with on a network.
+type Preset struct {
+	Name    string
+	ChainID *big.Int
+	// AVSAddress is the mev-commit AVS contract.
+	AVSAddress common.Address
+	// DelegationManagerAddress is the EigenLayer DelegationManager contract.
+	DelegationManagerAddress common.Address
+	// AVSDirectoryAddress is the EigenLayer AVSDirectory contract. Zero if it
+	// is only known from the AVS contract, as on local devnets.
+	AVSDirectoryAddress common.Address
+}
+
+var presets = []Preset{
+	{
+		Name:                     "mainnet",
+		ChainID:                  big.NewInt(1),
+		AVSAddress:               common.HexToAddress("0xBc77233855e3274E1903771675Eb71E602D9DC2e"),
+		DelegationManagerAddress: common.HexToAddress("0x39053D51B77DC0d36036Fc1fCc8Cb819df8Ef37A"),
+		AVSDirectoryAddress:      common.HexToAddress("0x135DDa560e946695d6f155dACaFC6f1F25C1F5AF"),
+	},
+	{
+		Name:                     "holesky",
+		ChainID:                  big.NewInt(17000),
+		AVSAddress:               common.HexToAddress("0xEDEDB8ed37A43Fd399108A44646B85b780D85DD4"),
+		DelegationManagerAddress: common.HexToAddress("0xA44151489861Fe9e3055d95adC98FbD462B948e7"),
+		AVSDirectoryAddress:      common.HexToAddress("0x055733000064333CaDDbC92763c58BF0192fFeBf"),
+	},
+	{
+		// Contracts deployed by the mev-commit local devnet scripts on anvil.
+		Name:                     "local",
+		ChainID:                  big.NewInt(31337),
+		AVSAddress:               common.HexToAddress("0x5FC8d32690cc91D4c39d9d3abcBD16989F875707"),
+		DelegationManagerAddress: common.HexToAddress("0xe7f1725E7734CE288F8367e1Bb143E90bb3F0512"),
+	},
+}
+
+// Names returns the names of all presets.
+func Names() []string {
+	names := make([]string, len(presets))
+	for i, p := range presets {
+		names[i] = p.Name
+	}
+	return names
+}
+
+// Lookup returns the preset with the given name, checking that it is for the
+// chain with the given ID.
+func Lookup(name string, chainID *big.Int) (Preset, error) {
+	i := slices.IndexFunc(presets, func(p Preset) bool { return p.Name == name })
+	if i < 0 {
+		return Preset{}, fmt.Errorf("unknown network %q, options are %s", name, strings.Join(Names(), ", "))
+	}
+	preset := presets[i]
+	if chainID.Cmp(preset.ChainID) != 0 {
+		return Preset{}, fmt.Errorf("network %s has chain ID %s but operator config has %s",
+			name, preset.ChainID, chainID)
+	}
+	return preset, nil
+}
@@ -0,0 +1,61 @@
+package network_test
+
+import (
+	"eigen-operator-cli/pkg/network"
+	"math/big"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestLookup(t *testing.T) {
+	testCases := []struct {
+		name              string
+		network           string
+		chainID           *big.Int
+		expectedAVS       string
+		errExpectedOutput string
+	}{
+		{
+			name:        "mainnet",
+			network:     "mainnet",
+			chainID:     big.NewInt(1),
+			expectedAVS: "0xBc77233855e3274E1903771675Eb71E602D9DC2e",
+		},
+		{
+			name:        "holesky",
+			network:     "holesky",
+			chainID:     big.NewInt(17000),
+			expectedAVS: "0xEDEDB8ed37A43Fd399108A44646B85b780D85DD4",
+		},
+		{
+			name:        "local",
+			network:     "local",
+			chainID:     big.NewInt(31337),
+			expectedAVS: "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+		},
+		{
+			name:              "error, chain ID mismatch",
+			network:           "mainnet",
+			chainID:           big.NewInt(17000),
+			errExpectedOutput: "network mainnet has chain ID 1 but operator config has 17000",
+		},
+		{
+			name:              "error, unknown network",
+			network:           "sepolia",
+			chainID:           big.NewInt(11155111),
+			errExpectedOutput: `unknown network "sepolia", options are mainnet, holesky, local`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			preset, err := network.Lookup(tc.network, tc.chainID)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedAVS, preset.AVSAddress.Hex())
+		})
+	}
+}
@@ -0,0 +1,180 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/urfave/cli/v2"
+)
+
+// errNonceMined is returned when the pending tx at a nonce was mined before its
+// replacement, leaving nothing to cancel.
+var errNonceMined = errors.New("pending tx mined before it could be replaced")
+
+// CancelPending replaces every pending transaction of the signing account with a
+// zero-value self-transfer at the same nonce, priced above the pending transaction
+// and submitted according to the submission mode.
+func (c *Command) CancelPending(ctx *cli.Context) error {
+	c.Logger.Info("Cancelling pending transactions...")
+	if err := c.initializeClient(ctx); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	if err := c.initializeSigner(ctx.Context); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	from := c.signer.Address()
+	nonceManager, err := tx.NewNonceManager(ctx.Context, c.ethClient, from, false)
+	if err != nil {
+		return fmt.Errorf("failed to initialize nonce manager: %w", err)
+	}
+	nonces := nonceManager.PendingNonces()
+	if len(nonces) == 0 {
+		c.Logger.Info("no pending transactions found", "account", from.Hex())
+		return nil
+	}
+	c.Logger.Info("pending transactions found", "account", from.Hex(), "nonces", nonces)
+
+	for _, nonce := range nonces {
+		if c.DryRun {
+			c.Logger.Info("dry run, would cancel pending transaction", "nonce", nonce)
+			continue
+		}
+		receipt, err := c.cancelNonce(ctx.Context, nonce)
+		if errors.Is(err, errNonceMined) {
+			c.Logger.Info("pending transaction mined before cancellation", "nonce", nonce)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("failed to cancel tx at nonce %d: %w", nonce, err)
+		}
+		if receipt == nil {
+			continue
+		}
+		c.Logger.Info("pending transaction cancelled",
+			"nonce", nonce,
+			"txHash", receipt.TxHash.Hex(),
+			"block", receipt.BlockNumber,
+		)
+	}
+
+	if c.DryRun || c.submissionMode() == SubmissionModeFireAndForget {
+		return nil
+	}
+	if err := nonceManager.Sync(ctx.Context); err != nil {
+		return err
+	}
+	if nonceManager.HasPending() {
+		return fmt.Errorf("pending transactions remain at nonces %v", nonceManager.PendingNonces())
+	}
+	c.Logger.Info("CancelPending complete", "nonces", nonces)
+	return nil
+}
+
+// cancelNonce submits a zero-value self-transfer at nonce. The returned receipt is
+// nil in fire-and-forget mode.
+func (c *Command) cancelNonce(ctx context.Context, nonce uint64) (*ethtypes.Receipt, error) {
+	opts := c.transactOpts(ctx)
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasLimit = params.TxGas
+
+	if err := c.setFeeParams(ctx, opts); err != nil {
+		return nil, err
+	}
+	mode, err := c.priceReplacement(ctx, opts, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	signTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		to := opts.From
+		var cancelTx *ethtypes.Transaction
+		if opts.GasPrice != nil {
+			cancelTx = ethtypes.NewTx(&ethtypes.LegacyTx{
+				Nonce:    opts.Nonce.Uint64(),
+				GasPrice: opts.GasPrice,
+				Gas:      opts.GasLimit,
+				To:       &to,
+				Value:    big.NewInt(0),
+			})
+		} else {
+			cancelTx = ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+				ChainID:   c.chainID,
+				Nonce:     opts.Nonce.Uint64(),
+				GasTipCap: opts.GasTipCap,
+				GasFeeCap: opts.GasFeeCap,
+				Gas:       opts.GasLimit,
+				To:        &to,
+				Value:     big.NewInt(0),
+			})
+		}
+		signedTx, err := opts.Signer(opts.From, cancelTx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign cancel tx: %w", err)
+		}
+		c.Logger.Debug("cancel tx signed",
+			"txHash", signedTx.Hash().Hex(),
+			"gasTipCap", signedTx.GasTipCap(),
+			"gasFeeCap", signedTx.GasFeeCap(),
+		)
+		return signedTx, nil
+	}
+
+	receipt, err := c.submitWithMode(ctx, "cancel", mode, opts, signTx)
+	// The pending tx was mined before a replacement was sent or could replace it.
+	if errors.Is(err, tx.ErrNonceTooLow) || errors.Is(err, tx.ErrNonceConsumed) {
+		return nil, errNonceMined
+	}
+	return receipt, err
+}
+
+// priceReplacement boosts opts, priced for a new tx, above the higher of the
+// fees of the pending tx at nonce and the suggestion, since the pending tx may
+// have been boosted already. Without the pending tx's fees the replacement may
+// be underpriced, so the returned submission mode boosts it until it is accepted.
+func (c *Command) priceReplacement(ctx context.Context, opts *bind.TransactOpts, nonce uint64) (SubmissionMode, error) {
+	mode := c.submissionMode()
+	pendingTx, err := c.ethClient.TransactionBySenderAndNonce(ctx, opts.From, nonce)
+	if err == nil {
+		raiseToPendingFees(opts, pendingTx)
+	} else {
+		if mode == SubmissionModeWait || mode == SubmissionModeFireAndForget {
+			mode = SubmissionModeBoost
+		}
+		c.Logger.Warn("fees of pending transaction unknown, boosting its replacement until it is accepted",
+			"nonce", nonce, "mode", mode, "error", err)
+	}
+	policy := c.retryPolicy()
+	if err := tx.BoostTipForTransactOpts(ctx, opts, c.ethClient, policy.Strategy, policy.BoostPercent, c.Logger); err != nil {
+		return "", fmt.Errorf("failed to boost gas tip: %w", err)
+	}
+	return mode, nil
+}
+
+// raiseToPendingFees raises the fee params of opts to those of pendingTx where
+// these are higher, so that boosting opts outbids pendingTx.
+func raiseToPendingFees(opts *bind.TransactOpts, pendingTx *ethtypes.Transaction) {
+	if opts.GasPrice != nil {
+		opts.GasPrice = bigMax(opts.GasPrice, pendingTx.GasPrice())
+		return
+	}
+	// Both are the gas price for a legacy pendingTx.
+	opts.GasTipCap = bigMax(opts.GasTipCap, pendingTx.GasTipCap())
+	opts.GasFeeCap = bigMax(opts.GasFeeCap, pendingTx.GasFeeCap())
+}
+
+func bigMax(a, b *big.Int) *big.Int {
+	if a.Cmp(b) < 0 {
+		return new(big.Int).Set(b)
+	}
+	return a
+}
@@ -0,0 +1,391 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/multiclient"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+const gwei = 1_000_000_000
+
+// cancelEthStandIn serves the eth methods used to replace a pending tx over JSON-RPC.
+// Every tx sent is mined right away unless sendErrs rejects it.
+type cancelEthStandIn struct {
+	mu       sync.Mutex
+	gasTip   *big.Int
+	gasPrice *big.Int
+	nonce    uint64
+	sendErrs []error
+	sent     []*ethtypes.Transaction
+
+	// pendingNonce, if set, is the pending nonce of the account.
+	pendingNonce uint64
+}
+
+func (e *cancelEthStandIn) GetBlockByNumber(number string, full bool) *ethtypes.Header {
+	return &ethtypes.Header{
+		Number:     big.NewInt(100),
+		Difficulty: big.NewInt(0),
+		BaseFee:    big.NewInt(10 * gwei),
+	}
+}
+
+func (e *cancelEthStandIn) MaxPriorityFeePerGas() *hexutil.Big {
+	return (*hexutil.Big)(e.gasTip)
+}
+
+func (e *cancelEthStandIn) GasPrice() *hexutil.Big {
+	return (*hexutil.Big)(e.gasPrice)
+}
+
+func (e *cancelEthStandIn) BlockNumber() hexutil.Uint64 {
+	return 100
+}
+
+func (e *cancelEthStandIn) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	if block == "pending" && e.pendingNonce != 0 {
+		return hexutil.Uint64(e.pendingNonce)
+	}
+	return hexutil.Uint64(e.nonce)
+}
+
+func (e *cancelEthStandIn) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	signedTx := new(ethtypes.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return common.Hash{}, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.sendErrs) > 0 {
+		err := e.sendErrs[0]
+		e.sendErrs = e.sendErrs[1:]
+		if err != nil {
+			return common.Hash{}, err
+		}
+	}
+	e.sent = append(e.sent, signedTx)
+	return signedTx.Hash(), nil
+}
+
+func (e *cancelEthStandIn) GetTransactionReceipt(hash common.Hash) *ethtypes.Receipt {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for _, sentTx := range e.sent {
+		if sentTx.Hash() == hash {
+			return &ethtypes.Receipt{
+				Type:        sentTx.Type(),
+				Status:      ethtypes.ReceiptStatusSuccessful,
+				Logs:        []*ethtypes.Log{},
+				TxHash:      hash,
+				BlockHash:   common.HexToHash("0x64"),
+				BlockNumber: big.NewInt(100),
+			}
+		}
+	}
+	return nil
+}
+
+func (e *cancelEthStandIn) sentTxs() []*ethtypes.Transaction {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.sent
+}
+
+// senderNonceStandIn serves eth_getTransactionBySenderAndNonce on top of cancelEthStandIn.
+type senderNonceStandIn struct {
+	*cancelEthStandIn
+	pendingTx *ethtypes.Transaction
+}
+
+func (e senderNonceStandIn) GetTransactionBySenderAndNonce(
+	from common.Address, nonce hexutil.Uint64) *ethtypes.Transaction {
+	return e.pendingTx
+}
+
+func TestCancelNonce(t *testing.T) {
+	const nonce = 3
+	tests := []struct {
+		name             string
+		mode             SubmissionMode
+		pendingTip       int64
+		pendingFeeCap    int64
+		sendErrs         []error
+		expectedFees     [][2]int64
+		expectedReceipt  bool
+		expectedError    error
+		expectedErrorMsg string
+	}{
+		{
+			name:          "boosted above pending fees",
+			mode:          SubmissionModeFireAndForget,
+			pendingTip:    5 * gwei,
+			pendingFeeCap: 50 * gwei,
+			// 1.1 * 5 gwei + 1 tip, and 1.1 * 45 gwei base fee.
+			expectedFees: [][2]int64{{5.5*gwei + 1, 55*gwei + 1}},
+		},
+		{
+			name:          "boosted above suggestion",
+			mode:          SubmissionModeWait,
+			pendingTip:    1 * gwei,
+			pendingFeeCap: 10 * gwei,
+			// 1.1 * 2 gwei + 1 tip, and 1.1 * 28 gwei base fee.
+			expectedFees:    [][2]int64{{2.2*gwei + 1, 33*gwei + 1}},
+			expectedReceipt: true,
+		},
+		{
+			name:             "error, underpriced with pending fees known",
+			mode:             SubmissionModeWait,
+			pendingTip:       5 * gwei,
+			pendingFeeCap:    50 * gwei,
+			sendErrs:         []error{errors.New("replacement transaction underpriced")},
+			expectedErrorMsg: "failed to submit tx: replacement transaction underpriced",
+		},
+		{
+			name:     "pending fees unknown, boosted until accepted",
+			mode:     SubmissionModeWait,
+			sendErrs: []error{errors.New("replacement transaction underpriced")},
+			expectedFees: [][2]int64{
+				// Boosted from the suggestion once before sending, and again after
+				// the replacement is rejected.
+				{2.42*gwei + 2, 36.3*gwei + 2},
+			},
+			expectedReceipt: true,
+		},
+		{
+			name:            "pending fees unknown, fire-and-forget boosted until mined",
+			mode:            SubmissionModeFireAndForget,
+			expectedFees:    [][2]int64{{2.2*gwei + 1, 33*gwei + 1}},
+			expectedReceipt: true,
+		},
+		{
+			name:          "pending tx mined before replacement",
+			mode:          SubmissionModeWait,
+			pendingTip:    5 * gwei,
+			pendingFeeCap: 50 * gwei,
+			sendErrs:      []error{errors.New("nonce too low")},
+			expectedError: errNonceMined,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newOfflineCommand(t)
+			assert.NilError(t, c.initializeSigner(context.Background()))
+			c.chainID = offlineChainID
+			c.SubmissionMode = tc.mode
+			c.RetryPolicy = &tx.RetryPolicy{
+				Attempts:     3,
+				Wait:         100 * time.Millisecond,
+				BoostPercent: 10,
+			}
+
+			standIn := &cancelEthStandIn{
+				gasTip:   big.NewInt(2 * gwei),
+				gasPrice: big.NewInt(30 * gwei),
+				nonce:    nonce,
+				sendErrs: tc.sendErrs,
+			}
+			var eth any = standIn
+			if tc.pendingFeeCap != 0 {
+				key, err := crypto.GenerateKey()
+				assert.NilError(t, err)
+				pendingTx, err := ethtypes.SignNewTx(key, ethtypes.LatestSignerForChainID(offlineChainID),
+					&ethtypes.DynamicFeeTx{
+						ChainID:   offlineChainID,
+						Nonce:     nonce,
+						GasTipCap: big.NewInt(tc.pendingTip),
+						GasFeeCap: big.NewInt(tc.pendingFeeCap),
+						Gas:       21000,
+					})
+				assert.NilError(t, err)
+				eth = senderNonceStandIn{cancelEthStandIn: standIn, pendingTx: pendingTx}
+			}
+			server := rpc.NewServer()
+			assert.NilError(t, server.RegisterName("eth", eth))
+			t.Cleanup(server.Stop)
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+			client, err := multiclient.Dial(context.Background(), []string{httpServer.URL}, time.Second, slog.Default())
+			assert.NilError(t, err)
+			c.ethClient = client
+
+			receipt, err := c.cancelNonce(context.Background(), nonce)
+			switch {
+			case tc.expectedError != nil:
+				assert.Assert(t, errors.Is(err, tc.expectedError), "unexpected error: %v", err)
+				return
+			case tc.expectedErrorMsg != "":
+				assert.ErrorContains(t, err, tc.expectedErrorMsg)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedReceipt, receipt != nil)
+
+			sent := standIn.sentTxs()
+			assert.Equal(t, len(tc.expectedFees), len(sent))
+			for i, fees := range tc.expectedFees {
+				assert.Equal(t, uint64(nonce), sent[i].Nonce())
+				assert.Equal(t, c.signer.Address(), *sent[i].To())
+				assert.Equal(t, big.NewInt(fees[0]).String(), sent[i].GasTipCap().String())
+				assert.Equal(t, big.NewInt(fees[1]).String(), sent[i].GasFeeCap().String())
+			}
+		})
+	}
+}
+
+func TestInitializeTxParamsReplaceNonce(t *testing.T) {
+	const nonce = 3
+	tests := []struct {
+		name         string
+		pendingKnown bool
+		expectedMode SubmissionMode
+		expectedFees [2]int64
+	}{
+		{
+			name:         "priced above pending tx",
+			pendingKnown: true,
+			expectedMode: SubmissionModeWait,
+			// 1.1 * 5 gwei + 1 tip, and 1.1 * 45 gwei base fee.
+			expectedFees: [2]int64{5.5*gwei + 1, 55*gwei + 1},
+		},
+		{
+			name:         "pending fees unknown, boosted until accepted",
+			expectedMode: SubmissionModeBoost,
+			// 1.1 * 2 gwei + 1 tip, and 1.1 * 28 gwei base fee.
+			expectedFees: [2]int64{2.2*gwei + 1, 33*gwei + 1},
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newOfflineCommand(t)
+			assert.NilError(t, c.initializeSigner(context.Background()))
+			c.chainID = offlineChainID
+			c.SubmissionMode = SubmissionModeWait
+			c.ReplaceNonce = new(uint64)
+			*c.ReplaceNonce = nonce
+			c.RetryPolicy = &tx.RetryPolicy{BoostPercent: 10}
+
+			standIn := &cancelEthStandIn{
+				gasTip:       big.NewInt(2 * gwei),
+				gasPrice:     big.NewInt(30 * gwei),
+				nonce:        nonce,
+				pendingNonce: nonce + 1,
+			}
+			var eth any = standIn
+			if tc.pendingKnown {
+				key, err := crypto.GenerateKey()
+				assert.NilError(t, err)
+				pendingTx, err := ethtypes.SignNewTx(key, ethtypes.LatestSignerForChainID(offlineChainID),
+					&ethtypes.DynamicFeeTx{
+						ChainID:   offlineChainID,
+						Nonce:     nonce,
+						GasTipCap: big.NewInt(5 * gwei),
+						GasFeeCap: big.NewInt(50 * gwei),
+						Gas:       21000,
+					})
+				assert.NilError(t, err)
+				eth = senderNonceStandIn{cancelEthStandIn: standIn, pendingTx: pendingTx}
+			}
+			server := rpc.NewServer()
+			assert.NilError(t, server.RegisterName("eth", eth))
+			t.Cleanup(server.Stop)
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+			client, err := multiclient.Dial(context.Background(), []string{httpServer.URL}, time.Second, slog.Default())
+			assert.NilError(t, err)
+			c.ethClient = client
+
+			opts := &bind.TransactOpts{}
+			assert.NilError(t, c.initializeTxParams(cliContext(), opts, c.signer.Address()))
+			assert.Equal(t, uint64(nonce), opts.Nonce.Uint64())
+			assert.Equal(t, tc.expectedMode, c.SubmissionMode)
+			assert.Equal(t, big.NewInt(tc.expectedFees[0]).String(), opts.GasTipCap.String())
+			assert.Equal(t, big.NewInt(tc.expectedFees[1]).String(), opts.GasFeeCap.String())
+		})
+	}
+}
+
+func TestRaiseToPendingFees(t *testing.T) {
+	pendingDynamic := ethtypes.NewTx(&ethtypes.DynamicFeeTx{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(50)})
+	pendingLegacy := ethtypes.NewTx(&ethtypes.LegacyTx{GasPrice: big.NewInt(40)})
+
+	tests := []struct {
+		name              string
+		gasTip, gasFeeCap int64
+		gasPrice          int64
+		pendingTx         *ethtypes.Transaction
+		expectedTip       int64
+		expectedFeeCap    int64
+		expectedGasPrice  int64
+	}{
+		{
+			name:           "pending fees higher",
+			gasTip:         2,
+			gasFeeCap:      30,
+			pendingTx:      pendingDynamic,
+			expectedTip:    5,
+			expectedFeeCap: 50,
+		},
+		{
+			name:           "suggested tip higher",
+			gasTip:         7,
+			gasFeeCap:      30,
+			pendingTx:      pendingDynamic,
+			expectedTip:    7,
+			expectedFeeCap: 50,
+		},
+		{
+			name:           "suggestion higher",
+			gasTip:         7,
+			gasFeeCap:      70,
+			pendingTx:      pendingDynamic,
+			expectedTip:    7,
+			expectedFeeCap: 70,
+		},
+		{
+			name:           "legacy pending tx",
+			gasTip:         2,
+			gasFeeCap:      30,
+			pendingTx:      pendingLegacy,
+			expectedTip:    40,
+			expectedFeeCap: 40,
+		},
+		{
+			name:             "legacy replacement",
+			gasPrice:         30,
+			pendingTx:        pendingLegacy,
+			expectedGasPrice: 40,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			opts := &bind.TransactOpts{}
+			if tc.gasPrice != 0 {
+				opts.GasPrice = big.NewInt(tc.gasPrice)
+			} else {
+				opts.GasTipCap, opts.GasFeeCap = big.NewInt(tc.gasTip), big.NewInt(tc.gasFeeCap)
+			}
+			raiseToPendingFees(opts, tc.pendingTx)
+			if tc.gasPrice != 0 {
+				assert.Equal(t, tc.expectedGasPrice, opts.GasPrice.Int64())
+				return
+			}
+			assert.Equal(t, tc.expectedTip, opts.GasTipCap.Int64())
+			assert.Equal(t, tc.expectedFeeCap, opts.GasFeeCap.Int64())
+		})
+	}
+}
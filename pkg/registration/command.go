@@ -1,12 +1,18 @@
 package registration
 
 import (
+	"context"
+	"eigen-operator-cli/pkg/multiclient"
+	"eigen-operator-cli/pkg/rpcauth"
+	"eigen-operator-cli/pkg/signer"
 	"eigen-operator-cli/pkg/tx"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"net/http"
 	"os"
 	"path/filepath"
+	"time"
 
 	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
 	eigencliutils "github.com/Layr-Labs/eigenlayer-cli/pkg/utils"
@@ -15,33 +21,113 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
 	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
 	"github.com/urfave/cli/v2"
 )
 
+// defaultSignatureExpiry is how long an operator's AVS registration signature stays valid.
+const defaultSignatureExpiry = time.Hour
+
+// defaultPrivateTargetBlocks is how many blocks a tx sent to a private relay
+// targets before it is boosted and sent again, as Flashbots does by default.
+const defaultPrivateTargetBlocks = 25
+
+// SubmissionMode selects how transactions are submitted and waited for.
+type SubmissionMode string
+
+const (
+	// SubmissionModeWait sends a tx once and waits for it to be mined.
+	SubmissionModeWait SubmissionMode = "wait"
+	// SubmissionModeBoost resends a tx with boosted fees until it is mined,
+	// following the retry policy.
+	SubmissionModeBoost SubmissionMode = "boost"
+	// SubmissionModeFireAndForget sends a tx once and exits without waiting.
+	SubmissionModeFireAndForget SubmissionMode = "fire-and-forget"
+	// SubmissionModePrivate sends a tx to a private relay, keeping it out of the
+	// public mempool, and resends it with boosted fees for the next target
+	// blocks until it is mined.
+	SubmissionModePrivate SubmissionMode = "private"
+)
+
+// SubmissionModes lists the supported submission modes.
+var SubmissionModes = []SubmissionMode{
+	SubmissionModeWait,
+	SubmissionModeBoost,
+	SubmissionModeFireAndForget,
+	SubmissionModePrivate,
+}
+
 type Command struct {
-	OperatorConfig      *eigenclitypes.OperatorConfig
-	KeystorePassword    string
-	MevCommitAVSAddress string
-	BoostGasParams      bool
-	Logger              *slog.Logger
-	keystore            *keystore.KeyStore
-	account             accounts.Account
-	ethClient           *ethclient.Client
-	avsT                *avs.MevcommitavsTransactor
-	avsC                *avs.MevcommitavsCaller
-	dmC                 *dm.ContractDelegationManagerCaller
-	tOpts               *bind.TransactOpts
+	OperatorConfig       *eigenclitypes.OperatorConfig
+	KeystorePassword     string
+	MevCommitAVSAddress  string
+	AVSDirectoryAddress  string
+	SubmissionMode       SubmissionMode
+	PrivateRPCURL        string
+	PrivateRPCMethod     tx.RelayMethod
+	RelaySigningKeyFile  string
+	PrivateTargetBlocks  uint64
+	RPCURLs              []string
+	RPCTimeout           time.Duration
+	RPCQuorum            int
+	RPCAuth              rpcauth.Config
+	DryRun               bool
+	Force                bool
+	GasLimitMultiplier   float64
+	MaxGasLimit          uint64
+	GasLimit             uint64
+	SignatureExpiry      time.Duration
+	Action               string
+	BundleFile           string
+	OutputFile           string
+	ValidatorPubkeys     []string
+	ValidatorPubkeysFile string
+	DepositDataFile      string
+	PodOwner             string
+	BatchSize            int
+	BatchGasBudget       uint64
+	ReplaceNonce         *uint64
+	FillNonceGaps        bool
+	GasStrategy          tx.GasStrategy
+	AccessList           bool
+	FeeCeiling           *tx.FeeCeiling
+	RetryPolicy          *tx.RetryPolicy
+	Confirmations        uint64
+	OutputFormat         string
+	Logger               *slog.Logger
+	signer               signer.Signer
+	ethClient            *multiclient.Client
+	quorumClient         *multiclient.Client
+	relay                *tx.Relay
+	avsT                 *avs.MevcommitavsTransactor
+	avsC                 *avs.MevcommitavsCaller
+	dmC                  *dm.ContractDelegationManagerCaller
+	tOpts                *bind.TransactOpts
+	nonceManager         *tx.NonceManager
+	chainID              *big.Int
 }
 
-func (c *Command) initialize(ctx *cli.Context) error {
-	ethClient, err := ethclient.Dial(c.OperatorConfig.EthRPCUrl)
+// initializeClient connects to the configured RPC endpoints and binds the AVS
+// and delegation manager contracts. It needs no access to the keystore.
+func (c *Command) initializeClient(ctx *cli.Context) error {
+	opts, err := c.RPCAuth.ClientOptions()
+	if err != nil {
+		return fmt.Errorf("failed to load rpc credentials: %w", err)
+	}
+	ethClient, err := multiclient.Dial(ctx.Context, c.rpcURLs(), c.RPCTimeout, c.Logger, opts...)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Ethereum node: %w", err)
 	}
 	c.ethClient = ethClient
 
+	quorumClient, err := ethClient.WithQuorum(max(c.RPCQuorum, 1))
+	if err != nil {
+		return err
+	}
+	c.quorumClient = quorumClient
+
 	chainID, err := ethClient.ChainID(ctx.Context)
 	if err != nil {
 		c.Logger.Error("failed to get chain ID", "error", err)
@@ -52,8 +138,114 @@ func (c *Command) initialize(ctx *cli.Context) error {
 			chainID.String(), c.OperatorConfig.ChainId.String())
 	}
 	c.Logger.Info("Chain ID", "chainID", chainID)
+	c.chainID = chainID
+
+	if c.submissionMode() == SubmissionModePrivate {
+		if c.PrivateRPCURL == "" {
+			return fmt.Errorf("a private rpc url is required for submission mode %q", SubmissionModePrivate)
+		}
+		method := c.PrivateRPCMethod
+		if method == "" {
+			method = tx.RelayMethodRawTransaction
+		}
+		relayOpts, err := c.relayClientOptions()
+		if err != nil {
+			return err
+		}
+		relay, err := tx.DialRelay(ctx.Context, c.PrivateRPCURL, method, relayOpts...)
+		if err != nil {
+			return err
+		}
+		c.relay = relay
+	}
+
+	avsAddress := common.HexToAddress(c.MevCommitAVSAddress)
+	c.Logger.Debug("avs address", "address", avsAddress.Hex())
+
+	avsT, err := avs.NewMevcommitavsTransactor(avsAddress, c.ethClient)
+	if err != nil {
+		return fmt.Errorf("failed to create avs transactor: %w", err)
+	}
+	c.avsT = avsT
+
+	// Registration info and deregistration periods are read from a quorum of
+	// endpoints, if configured, since commands act on them.
+	avsC, err := avs.NewMevcommitavsCaller(avsAddress, c.quorumClient)
+	if err != nil {
+		return fmt.Errorf("failed to create avs caller: %w", err)
+	}
+	c.avsC = avsC
+
+	dmAddr := common.HexToAddress(c.OperatorConfig.ELDelegationManagerAddress)
+	c.Logger.Debug("delegation manager address", "address", dmAddr.Hex())
+
+	dmC, err := dm.NewContractDelegationManagerCaller(dmAddr, c.ethClient)
+	if err != nil {
+		return fmt.Errorf("failed to create delegation manager: %w", err)
+	}
+	c.dmC = dmC
+
+	return nil
+}
+
+func (c *Command) initialize(ctx *cli.Context) error {
+	if err := c.initializeClient(ctx); err != nil {
+		return err
+	}
+	if err := c.verifyContracts(ctx.Context); err != nil {
+		return err
+	}
+	if err := c.initializeSigner(ctx.Context); err != nil {
+		return err
+	}
+
+	tOpts := c.transactOpts(ctx.Context)
+	if err := c.initializeTxParams(ctx, tOpts, c.signer.Address()); err != nil {
+		return err
+	}
+	c.tOpts = tOpts
+
+	return nil
+}
+
+// transactOpts returns transact opts signed by the command's signer. With
+// AccessList set, the legacy txs sent on chains without EIP-1559 are sent as
+// access-list txs instead.
+func (c *Command) transactOpts(ctx context.Context) *bind.TransactOpts {
+	opts := signer.NewTransactOpts(ctx, c.signer, c.chainID)
+	if c.AccessList {
+		opts.Signer = tx.AccessListSigner(ctx, c.ethClient, c.chainID, opts.Signer)
+	}
+	return opts
+}
+
+// initializeSigner sets up the signer selected by the operator config's signer_type.
+// It needs no connection to an RPC endpoint.
+func (c *Command) initializeSigner(ctx context.Context) error {
+	operatorAddr := common.HexToAddress(c.OperatorConfig.Operator.Address)
+	switch c.OperatorConfig.SignerType {
+	case eigenclitypes.LocalKeystoreSigner, "":
+		return c.initializeKeystoreSigner()
+	case eigenclitypes.Web3Signer:
+		url := c.OperatorConfig.Web3SignerConfig.Url
+		if url == "" {
+			return fmt.Errorf("signer_type %q requires web3.url in operator config", eigenclitypes.Web3Signer)
+		}
+		web3Signer, err := signer.NewWeb3Signer(ctx, url, operatorAddr)
+		if err != nil {
+			return err
+		}
+		c.signer = web3Signer
+		c.Logger.Debug("signer address", "address", c.signer.Address().Hex(), "web3signer", url)
+		return nil
+	default:
+		return fmt.Errorf("unsupported signer_type: %q", c.OperatorConfig.SignerType)
+	}
+}
 
-	_, err = os.Stat(c.OperatorConfig.PrivateKeyStorePath)
+// initializeKeystoreSigner unlocks the operator account in the local keystore.
+func (c *Command) initializeKeystoreSigner() error {
+	_, err := os.Stat(c.OperatorConfig.PrivateKeyStorePath)
 	if err != nil {
 		return fmt.Errorf("no keystore file found at path: %s", c.OperatorConfig.PrivateKeyStorePath)
 	}
@@ -74,8 +266,8 @@ func (c *Command) initialize(ctx *cli.Context) error {
 
 	dir := filepath.Dir(c.OperatorConfig.PrivateKeyStorePath)
 
-	keystore := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
-	ksAccounts := keystore.Accounts()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	ksAccounts := ks.Accounts()
 
 	var account accounts.Account
 	if len(ksAccounts) == 0 {
@@ -94,68 +286,163 @@ func (c *Command) initialize(ctx *cli.Context) error {
 		}
 	}
 
-	if err := keystore.Unlock(account, c.KeystorePassword); err != nil {
+	if err := ks.Unlock(account, c.KeystorePassword); err != nil {
 		return fmt.Errorf("failed to unlock account: %w", err)
 	}
 
-	c.keystore = keystore
-	c.account = account
+	c.signer = signer.NewKeystoreSigner(ks, account)
 
-	c.Logger.Debug("signer address", "address", c.account.Address.Hex())
+	c.Logger.Debug("signer address", "address", c.signer.Address().Hex())
 
-	pending, err := tx.PendingTransactionsExist(c.ethClient, ctx.Context, c.account.Address)
+	return nil
+}
+
+// initializeTxParams fills in the sender, nonce and fee params of tOpts. Unless
+// ReplaceNonce is set, it refuses to proceed if the sender already has pending
+// transactions. A replacement is priced above the pending transaction it replaces.
+func (c *Command) initializeTxParams(ctx *cli.Context, tOpts *bind.TransactOpts, from common.Address) error {
+	nonceManager, err := tx.NewNonceManager(ctx.Context, c.ethClient, from, c.FillNonceGaps)
 	if err != nil {
-		return fmt.Errorf("failed to check for pending transactions: %w", err)
+		return fmt.Errorf("failed to initialize nonce manager: %w", err)
 	}
-	if pending {
-		return fmt.Errorf("pending transactions found for signing operator account. " +
-			"Please cancel or wait for them to be mined before proceeding")
+	c.nonceManager = nonceManager
+
+	tOpts.From = from
+	if c.ReplaceNonce != nil {
+		nonce, err := nonceManager.Replace(*c.ReplaceNonce)
+		if err != nil {
+			return fmt.Errorf("failed to replace pending transaction: %w", err)
+		}
+		c.Logger.Warn("replacing pending transaction", "nonce", nonce)
+		tOpts.Nonce = new(big.Int).SetUint64(nonce)
+	} else {
+		if nonceManager.HasPending() {
+			return fmt.Errorf("pending transactions found for signing operator account at nonces %v. "+
+				"Please cancel them with cancel-pending or wait for them to be mined before proceeding, "+
+				"or replace one with --replace-nonce", nonceManager.PendingNonces())
+		}
+		tOpts.Nonce = new(big.Int).SetUint64(nonceManager.Next())
 	}
 
-	avsAddress := common.HexToAddress(c.MevCommitAVSAddress)
-	c.Logger.Debug("avs address", "address", avsAddress.Hex())
+	if err := c.setFeeParams(ctx.Context, tOpts); err != nil {
+		return err
+	}
+	if c.ReplaceNonce == nil {
+		return nil
+	}
+	mode, err := c.priceReplacement(ctx.Context, tOpts, tOpts.Nonce.Uint64())
+	if err != nil {
+		return err
+	}
+	c.SubmissionMode = mode
+	return nil
+}
 
-	avsT, err := avs.NewMevcommitavsTransactor(avsAddress, c.ethClient)
+// setFeeParams prices opts with the gas strategy, as an EIP-1559 tx or, on chains
+// that don't support it, as a legacy tx, which transactOpts signs as an
+// access-list tx if AccessList is set.
+func (c *Command) setFeeParams(ctx context.Context, opts *bind.TransactOpts) error {
+	london, err := tx.SupportsLondon(ctx, c.ethClient)
 	if err != nil {
-		return fmt.Errorf("failed to create avs transactor: %w", err)
+		return err
+	}
+	if !london {
+		gasPrice, err := c.gasStrategy().SuggestGasPrice(ctx, c.ethClient)
+		if err != nil {
+			return fmt.Errorf("failed to suggest gas price: %w", err)
+		}
+		c.Logger.Info("chain doesn't support EIP-1559, sending legacy transactions", "gasPrice", gasPrice)
+		opts.GasPrice = gasPrice
+		return nil
 	}
-	c.avsT = avsT
 
-	avsC, err := avs.NewMevcommitavsCaller(avsAddress, c.ethClient)
+	gasTip, gasPrice, err := c.gasStrategy().SuggestFees(ctx, c.ethClient)
 	if err != nil {
-		return fmt.Errorf("failed to create avs caller: %w", err)
+		return fmt.Errorf("failed to suggest gas tip cap and price: %w", err)
 	}
-	c.avsC = avsC
+	opts.GasFeeCap = gasPrice
+	opts.GasTipCap = gasTip
+	return nil
+}
 
-	dmAddr := common.HexToAddress(c.OperatorConfig.ELDelegationManagerAddress)
-	c.Logger.Debug("delegation manager address", "address", dmAddr.Hex())
+// gasStrategy returns the configured gas pricing strategy, defaulting to the
+// node's suggestions.
+func (c *Command) gasStrategy() tx.GasStrategy {
+	if c.GasStrategy == nil {
+		return tx.NodeGasStrategy{}
+	}
+	return c.GasStrategy
+}
 
-	dmC, err := dm.NewContractDelegationManagerCaller(dmAddr, c.ethClient)
-	if err != nil {
-		return fmt.Errorf("failed to create delegation manager: %w", err)
+// retryPolicy returns the configured retry schedule, or the default one, priced
+// by the command's gas strategy, bounded by its fee ceiling and waiting for its
+// confirmations. In private mode, attempts last as many blocks as txs target.
+func (c *Command) retryPolicy() tx.RetryPolicy {
+	policy := tx.DefaultRetryPolicy()
+	if c.RetryPolicy != nil {
+		policy = *c.RetryPolicy
 	}
-	c.dmC = dmC
+	policy.Strategy = c.gasStrategy()
+	policy.Ceiling = c.FeeCeiling
+	policy.Confirmations = c.Confirmations
+	if c.submissionMode() == SubmissionModePrivate {
+		// Every attempt waits out the blocks targeted at the relay.
+		policy.WaitBlocks = c.privateTargetBlocks()
+		policy.Backoff = tx.BackoffConstant
+	}
+	return policy
+}
 
-	tOpts, err := bind.NewKeyStoreTransactorWithChainID(keystore, account, chainID)
-	if err != nil {
-		c.Logger.Error("failed to get auth", "error", err)
-		return err
+// privateTargetBlocks returns how many blocks a tx sent to the private relay targets.
+func (c *Command) privateTargetBlocks() uint64 {
+	if c.PrivateTargetBlocks == 0 {
+		return defaultPrivateTargetBlocks
 	}
-	tOpts.From = account.Address
-	nonce, err := c.ethClient.PendingNonceAt(ctx.Context, account.Address)
-	if err != nil {
-		return fmt.Errorf("failed to get pending nonce: %w", err)
+	return c.PrivateTargetBlocks
+}
+
+// rpcURLs returns the configured RPC endpoints, or the operator config's one.
+func (c *Command) rpcURLs() []string {
+	if len(c.RPCURLs) > 0 {
+		return c.RPCURLs
 	}
-	tOpts.Nonce = big.NewInt(int64(nonce))
-	c.tOpts = tOpts
+	return []string{c.OperatorConfig.EthRPCUrl}
+}
+
+// submissionMode returns the configured submission mode, boost by default.
+func (c *Command) submissionMode() SubmissionMode {
+	if c.SubmissionMode == "" {
+		return SubmissionModeBoost
+	}
+	return c.SubmissionMode
+}
 
-	gasTip, gasPrice, err := tx.SuggestGasTipCapAndPrice(ctx.Context, c.ethClient)
+// relayClientOptions returns the rpc client options of the private relay,
+// signing every request over HTTP if a relay signing key file is set. The
+// credentials of the RPC endpoints are never sent to the relay.
+func (c *Command) relayClientOptions() ([]rpc.ClientOption, error) {
+	if c.RelaySigningKeyFile == "" {
+		return nil, nil
+	}
+	key, err := crypto.LoadECDSA(c.RelaySigningKeyFile)
 	if err != nil {
-		return fmt.Errorf("failed to suggest gas tip cap and price: %w", err)
+		return nil, fmt.Errorf("failed to load private rpc signing key: %w", err)
 	}
-	c.tOpts.GasFeeCap = gasPrice
-	c.tOpts.GasTipCap = gasTip
-	c.tOpts.GasLimit = 300000
+	client := &http.Client{Transport: tx.RelaySigningTransport(key, http.DefaultTransport)}
+	return []rpc.ClientOption{rpc.WithHTTPClient(client)}, nil
+}
 
-	return nil
+// deregBlocksRemaining reports how many blocks remain in the deregistration
+// period for a request made at requestHeight, and whether the period has passed.
+// A block number below the request height, as read from a lagging endpoint,
+// leaves the whole period remaining.
+func deregBlocksRemaining(blockNum, requestHeight, deregPeriod uint64) (uint64, bool) {
+	if blockNum < requestHeight {
+		return deregPeriod, false
+	}
+	blocksSinceDereg := blockNum - requestHeight
+	if blocksSinceDereg <= deregPeriod {
+		return deregPeriod - blocksSinceDereg, false
+	}
+	return 0, true
 }
@@ -0,0 +1,156 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/rpcauth"
+	"eigen-operator-cli/pkg/tx"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+func TestDeregBlocksRemaining(t *testing.T) {
+	tests := []struct {
+		name              string
+		blockNum          uint64
+		requestHeight     uint64
+		expectedRemaining uint64
+		expectedPassed    bool
+	}{
+		{
+			name:              "requested in this block",
+			blockNum:          100,
+			requestHeight:     100,
+			expectedRemaining: 10,
+		},
+		{
+			name:              "within period",
+			blockNum:          104,
+			requestHeight:     100,
+			expectedRemaining: 6,
+		},
+		{
+			name:          "last block of period",
+			blockNum:      110,
+			requestHeight: 100,
+		},
+		{
+			name:           "period passed",
+			blockNum:       111,
+			requestHeight:  100,
+			expectedPassed: true,
+		},
+		{
+			name:              "block number below request height",
+			blockNum:          99,
+			requestHeight:     100,
+			expectedRemaining: 10,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			remaining, passed := deregBlocksRemaining(tc.blockNum, tc.requestHeight, 10)
+			assert.Equal(t, tc.expectedRemaining, remaining)
+			assert.Equal(t, tc.expectedPassed, passed)
+		})
+	}
+}
+
+// relayStandIn accepts raw transactions over JSON-RPC.
+type relayStandIn struct{}
+
+func (relayStandIn) SendRawTransaction(raw hexutil.Bytes) common.Hash {
+	return crypto.Keccak256Hash(raw)
+}
+
+// headerRecorder serves an rpc service over HTTP, recording the headers of
+// every request.
+type headerRecorder struct {
+	mu      sync.Mutex
+	headers []http.Header
+}
+
+func (r *headerRecorder) serve(t *testing.T, service any) string {
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("eth", service))
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.Lock()
+		r.headers = append(r.headers, req.Header.Clone())
+		r.mu.Unlock()
+		server.ServeHTTP(w, req)
+	}))
+	t.Cleanup(httpServer.Close)
+	return httpServer.URL
+}
+
+func TestRelayWithoutRPCCredentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		signingKey bool
+	}{
+		{
+			name: "unsigned",
+		},
+		{
+			name:       "signed",
+			signingKey: true,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			headersFile := filepath.Join(dir, "headers")
+			assert.NilError(t, os.WriteFile(headersFile, []byte("Authorization: Bearer node-secret\n"), 0o600))
+			var signingKeyFile string
+			if tc.signingKey {
+				key, err := crypto.GenerateKey()
+				assert.NilError(t, err)
+				signingKeyFile = filepath.Join(dir, "relay.key")
+				assert.NilError(t, crypto.SaveECDSA(signingKeyFile, key))
+			}
+
+			node, relay := &headerRecorder{}, &headerRecorder{}
+			operConfig := &eigenclitypes.OperatorConfig{ELDelegationManagerAddress: offlineDM.Hex()}
+			operConfig.ChainId = *offlineChainID
+			c := &Command{
+				OperatorConfig:      operConfig,
+				MevCommitAVSAddress: offlineAVS.Hex(),
+				SubmissionMode:      SubmissionModePrivate,
+				PrivateRPCURL:       relay.serve(t, relayStandIn{}),
+				RelaySigningKeyFile: signingKeyFile,
+				RPCAuth:             rpcauth.Config{HeadersFile: headersFile},
+				RPCURLs:             []string{node.serve(t, verifyEthStandIn{t: t})},
+				Logger:              slog.Default(),
+			}
+			assert.NilError(t, c.initializeClient(cliContext()))
+
+			key, err := crypto.GenerateKey()
+			assert.NilError(t, err)
+			signedTx, err := ethtypes.SignNewTx(key, ethtypes.LatestSignerForChainID(offlineChainID),
+				&ethtypes.DynamicFeeTx{ChainID: offlineChainID, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2), Gas: 21000})
+			assert.NilError(t, err)
+			assert.NilError(t, c.relay.Send(context.Background(), signedTx, 1, 2))
+
+			assert.Equal(t, "Bearer node-secret", node.headers[0].Get("Authorization"))
+			assert.Assert(t, len(relay.headers) > 0)
+			for _, header := range relay.headers {
+				assert.Equal(t, "", header.Get("Authorization"))
+				assert.Equal(t, tc.signingKey, header.Get(tx.RelaySignatureHeader) != "")
+			}
+		})
+	}
+}
@@ -2,10 +2,10 @@ package registration
 
 import (
 	"context"
-	"eigen-operator-cli/pkg/tx"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/urfave/cli/v2"
 )
@@ -17,8 +17,39 @@ func (c *Command) DeregisterOperator(ctx *cli.Context) error {
 		return fmt.Errorf("failed to initialize: %w", err)
 	}
 
+	if err := c.checkDeregPreconditions(ctx.Context, c.signer.Address()); err != nil {
+		return err
+	}
+
+	buildTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		tx, err := c.avsT.DeregisterOperator(opts, c.signer.Address())
+		if err != nil {
+			return nil, fmt.Errorf("failed to deregister operator: %w", err)
+		}
+		return tx, nil
+	}
+
+	if c.DryRun {
+		return c.dryRun(ctx.Context, "DeregisterOperator", buildTx)
+	}
+
+	receipt, err := c.sendTx(ctx.Context, "DeregisterOperator", buildTx)
+	if err != nil || receipt == nil {
+		return err
+	}
+
+	c.Logger.Info("DeregisterOperator complete", "txHash", receipt.TxHash.Hex())
+	return nil
+}
+
+// checkDeregPreconditions verifies that operator has requested deregistration and that the
+// deregistration period has passed.
+func (c *Command) checkDeregPreconditions(ctx context.Context, operator common.Address) error {
 	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(
-		&bind.CallOpts{Context: ctx.Context}, c.account.Address)
+		&bind.CallOpts{Context: ctx}, operator)
 	if err != nil {
 		return fmt.Errorf("failed to get operator reg info: %w", err)
 	}
@@ -30,54 +61,19 @@ func (c *Command) DeregisterOperator(ctx *cli.Context) error {
 	}
 
 	operatorDeregPeriod, err := c.avsC.OperatorDeregPeriodBlocks(
-		&bind.CallOpts{Context: ctx.Context})
+		&bind.CallOpts{Context: ctx})
 	if err != nil {
 		return fmt.Errorf("failed to get operator deregistration period: %w", err)
 	}
-	blockNum, err := c.ethClient.BlockNumber(ctx.Context)
+	blockNum, err := c.quorumClient.BlockNumber(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get block number: %w", err)
 	}
-	blocksSinceDereg := blockNum - operatorRegInfo.DeregRequestHeight.BlockHeight.Uint64()
-	if blocksSinceDereg <= operatorDeregPeriod.Uint64() {
-		blocksRemaining := operatorDeregPeriod.Uint64() - blocksSinceDereg
+	blocksRemaining, ok := deregBlocksRemaining(blockNum,
+		operatorRegInfo.DeregRequestHeight.BlockHeight.Uint64(), operatorDeregPeriod.Uint64())
+	if !ok {
 		return fmt.Errorf("not enough blocks have passed since deregistration request. "+
 			"Please wait %d more blocks", blocksRemaining)
 	}
-
-	submitTx := func(
-		ctx context.Context,
-		opts *bind.TransactOpts,
-	) (*ethtypes.Transaction, error) {
-		tx, err := c.avsT.DeregisterOperator(c.tOpts, c.account.Address)
-		if err != nil {
-			return nil, fmt.Errorf("failed to deregister operator: %w", err)
-		}
-		c.Logger.Info("DeregisterOperator tx sent", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
-		return tx, nil
-	}
-
-	var receipt *ethtypes.Receipt
-	if c.BoostGasParams {
-		receipt, err = tx.WaitMinedWithRetry(ctx.Context, c.tOpts, submitTx, c.ethClient, c.Logger)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
-	} else {
-		tx, err := submitTx(ctx.Context, c.tOpts)
-		if err != nil {
-			return fmt.Errorf("failed to submit tx: %w", err)
-		}
-		c.Logger.Info("waiting for tx to be mined", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
-		receipt, err = bind.WaitMined(ctx.Context, c.ethClient, tx)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
-	}
-	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
-		return fmt.Errorf("receipt status unsuccessful: %d", receipt.Status)
-	}
-
-	c.Logger.Info("DeregisterOperator complete", "txHash", receipt.TxHash.Hex())
 	return nil
 }
@@ -0,0 +1,203 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+func (c *Command) RegisterLSTRestaker(ctx *cli.Context) error {
+	c.Logger.Info("Registering LST restaker...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	chosenValidators, err := readValidatorPubkeys(c.ValidatorPubkeys, c.ValidatorPubkeysFile, c.DepositDataFile)
+	if err != nil {
+		return err
+	}
+	if err := c.checkLSTRegisterPreconditions(ctx.Context, c.signer.Address(), chosenValidators); err != nil {
+		return err
+	}
+
+	buildTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		tx, err := c.avsT.RegisterLSTRestaker(opts, chosenValidators)
+		if err != nil {
+			return nil, fmt.Errorf("failed to register lst restaker: %w", err)
+		}
+		return tx, nil
+	}
+
+	return c.submitLSTTx(ctx.Context, "RegisterLSTRestaker", buildTx)
+}
+
+func (c *Command) RequestLSTRestakerDeregistration(ctx *cli.Context) error {
+	c.Logger.Info("Requesting LST restaker deregistration...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	if err := c.checkLSTRequestDeregPreconditions(ctx.Context, c.signer.Address()); err != nil {
+		return err
+	}
+
+	buildTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		tx, err := c.avsT.RequestLSTRestakerDeregistration(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to request lst restaker deregistration: %w", err)
+		}
+		return tx, nil
+	}
+
+	return c.submitLSTTx(ctx.Context, "RequestLSTRestakerDeregistration", buildTx)
+}
+
+func (c *Command) DeregisterLSTRestaker(ctx *cli.Context) error {
+	c.Logger.Info("Deregistering LST restaker...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	if err := c.checkLSTDeregPreconditions(ctx.Context, c.signer.Address()); err != nil {
+		return err
+	}
+
+	buildTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		tx, err := c.avsT.DeregisterLSTRestaker(opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to deregister lst restaker: %w", err)
+		}
+		return tx, nil
+	}
+
+	return c.submitLSTTx(ctx.Context, "DeregisterLSTRestaker", buildTx)
+}
+
+// submitLSTTx dry runs or sends the tx built by buildTx and checks its receipt.
+func (c *Command) submitLSTTx(
+	ctx context.Context,
+	name string,
+	buildTx tx.TxSubmitFunc,
+) error {
+	if c.DryRun {
+		return c.dryRun(ctx, name, buildTx)
+	}
+
+	receipt, err := c.sendTx(ctx, name, buildTx)
+	if err != nil || receipt == nil {
+		return err
+	}
+
+	c.Logger.Info(name+" complete", "txHash", receipt.TxHash.Hex())
+	return nil
+}
+
+// checkLSTRegisterPreconditions verifies that restaker is not yet registered, is
+// delegated to an operator registered with the AVS, and that every chosen
+// validator is opted in to the AVS.
+func (c *Command) checkLSTRegisterPreconditions(
+	ctx context.Context,
+	restaker common.Address,
+	chosenValidators [][]byte,
+) error {
+	opts := &bind.CallOpts{Context: ctx}
+
+	regInfo, err := c.avsC.GetLSTRestakerRegInfo(opts, restaker)
+	if err != nil {
+		return fmt.Errorf("failed to get lst restaker reg info: %w", err)
+	}
+	if regInfo.Exists {
+		return fmt.Errorf("signing lst restaker already registered")
+	}
+
+	operator, err := c.dmC.DelegatedTo(opts, restaker)
+	if err != nil {
+		return fmt.Errorf("failed to get operator delegated to by lst restaker: %w", err)
+	}
+	if operator == (common.Address{}) {
+		return fmt.Errorf("signing lst restaker is not delegated to an operator")
+	}
+	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(opts, operator)
+	if err != nil {
+		return fmt.Errorf("failed to get operator reg info: %w", err)
+	}
+	if !operatorRegInfo.Exists {
+		return fmt.Errorf("operator %s delegated to by lst restaker is not registered with the avs", operator.Hex())
+	}
+
+	for _, pubkey := range chosenValidators {
+		optedIn, err := c.avsC.IsValidatorOptedIn(opts, pubkey)
+		if err != nil {
+			return fmt.Errorf("failed to check if validator is opted in: %w", err)
+		}
+		if !optedIn {
+			return fmt.Errorf("chosen validator %s is not opted in to the avs", hexutil.Encode(pubkey))
+		}
+	}
+	return nil
+}
+
+// checkLSTRequestDeregPreconditions verifies that restaker is registered and has
+// not yet requested deregistration.
+func (c *Command) checkLSTRequestDeregPreconditions(ctx context.Context, restaker common.Address) error {
+	regInfo, err := c.avsC.GetLSTRestakerRegInfo(&bind.CallOpts{Context: ctx}, restaker)
+	if err != nil {
+		return fmt.Errorf("failed to get lst restaker reg info: %w", err)
+	}
+	if !regInfo.Exists {
+		return fmt.Errorf("signing lst restaker must be registered")
+	}
+	if regInfo.DeregRequestHeight.Exists {
+		return fmt.Errorf("signing lst restaker already requested deregistration")
+	}
+	return nil
+}
+
+// checkLSTDeregPreconditions verifies that restaker has requested deregistration
+// and that the deregistration period has passed.
+func (c *Command) checkLSTDeregPreconditions(ctx context.Context, restaker common.Address) error {
+	regInfo, err := c.avsC.GetLSTRestakerRegInfo(&bind.CallOpts{Context: ctx}, restaker)
+	if err != nil {
+		return fmt.Errorf("failed to get lst restaker reg info: %w", err)
+	}
+	if !regInfo.Exists {
+		return fmt.Errorf("signing lst restaker must be registered")
+	}
+	if !regInfo.DeregRequestHeight.Exists {
+		return fmt.Errorf("signing lst restaker must have requested deregistration")
+	}
+
+	lstDeregPeriod, err := c.avsC.LstRestakerDeregPeriodBlocks(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("failed to get lst restaker deregistration period: %w", err)
+	}
+	blockNum, err := c.quorumClient.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	blocksRemaining, ok := deregBlocksRemaining(blockNum,
+		regInfo.DeregRequestHeight.BlockHeight.Uint64(), lstDeregPeriod.Uint64())
+	if !ok {
+		return fmt.Errorf("not enough blocks have passed since deregistration request. "+
+			"Please wait %d more blocks", blocksRemaining)
+	}
+	return nil
+}
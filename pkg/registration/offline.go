@@ -0,0 +1,500 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+	"github.com/urfave/cli/v2"
+)
+
+// Actions that can be prepared for offline signing. They match the names of the
+// corresponding online commands.
+const (
+	ActionRegister              = "register"
+	ActionRequestDeregistration = "request-deregistration"
+	ActionDeregister            = "deregister"
+)
+
+// actionMethods maps each action to the AVS method its transaction calls.
+var actionMethods = map[string]string{
+	ActionRegister:              "registerOperator",
+	ActionRequestDeregistration: "requestOperatorDeregistration",
+	ActionDeregister:            "deregisterOperator",
+}
+
+// TxBundle is an operator transaction prepared online, signed offline and
+// broadcast online. Registration bundles carry the digest to sign instead of
+// calldata, which is only known once the digest is signed. Bundles priced with a
+// gas price are signed as access-list txs if they carry an access list, and as
+// legacy txs otherwise.
+type TxBundle struct {
+	Action       string               `json:"action"`
+	ChainID      *hexutil.Big         `json:"chainId"`
+	From         common.Address       `json:"from"`
+	To           common.Address       `json:"to"`
+	Nonce        hexutil.Uint64       `json:"nonce"`
+	GasLimit     hexutil.Uint64       `json:"gasLimit"`
+	GasTipCap    *hexutil.Big         `json:"gasTipCap,omitempty"`
+	GasFeeCap    *hexutil.Big         `json:"gasFeeCap,omitempty"`
+	GasPrice     *hexutil.Big         `json:"gasPrice,omitempty"`
+	Data         hexutil.Bytes        `json:"data,omitempty"`
+	AccessList   *ethtypes.AccessList `json:"accessList,omitempty"`
+	Registration *RegistrationDigest  `json:"registration,omitempty"`
+	SignedTx     hexutil.Bytes        `json:"signedTx,omitempty"`
+}
+
+// PrepareTx gathers everything needed to sign an operator transaction offline
+// and writes it to an unsigned transaction bundle. It needs no keystore.
+func (c *Command) PrepareTx(ctx *cli.Context) error {
+	c.Logger.Info("Preparing unsigned transaction...", "action", c.Action)
+	method, ok := actionMethods[c.Action]
+	if !ok {
+		return fmt.Errorf("unknown action: %q", c.Action)
+	}
+	// The AVS directory rejects a registration without a valid operator
+	// signature, so it can't be estimated before it is signed offline.
+	if c.Action == ActionRegister && c.GasLimit == 0 {
+		return fmt.Errorf("a registration can't be estimated before it is signed, set its gas limit with --gas-limit")
+	}
+	if err := c.initializeClient(ctx); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	if err := c.verifyContracts(ctx.Context); err != nil {
+		return err
+	}
+
+	operator := common.HexToAddress(c.OperatorConfig.Operator.Address)
+	c.tOpts = &bind.TransactOpts{}
+	if err := c.initializeTxParams(ctx, c.tOpts, operator); err != nil {
+		return err
+	}
+
+	bundle := TxBundle{
+		Action:    c.Action,
+		ChainID:   (*hexutil.Big)(c.chainID),
+		From:      operator,
+		To:        common.HexToAddress(c.MevCommitAVSAddress),
+		Nonce:     hexutil.Uint64(c.tOpts.Nonce.Uint64()),
+		GasTipCap: (*hexutil.Big)(c.tOpts.GasTipCap),
+		GasFeeCap: (*hexutil.Big)(c.tOpts.GasFeeCap),
+		GasPrice:  (*hexutil.Big)(c.tOpts.GasPrice),
+	}
+
+	switch c.Action {
+	case ActionRegister:
+		if err := c.checkRegisterPreconditions(ctx.Context, operator); err != nil {
+			return err
+		}
+		regDigest, err := c.operatorRegistrationDigest(ctx.Context, operator)
+		if err != nil {
+			return fmt.Errorf("failed to get registration digest: %w", err)
+		}
+		// The digest computed by the AVS directory is stored for the offline
+		// signer, which can't query it.
+		bundle.Registration = &regDigest
+		gasLimit, err := c.bundleGasLimit(ctx.Context, method, bundle, nil)
+		if err != nil {
+			return err
+		}
+		bundle.GasLimit = hexutil.Uint64(gasLimit)
+	case ActionRequestDeregistration, ActionDeregister:
+		check := c.checkRequestDeregPreconditions
+		if c.Action == ActionDeregister {
+			check = c.checkDeregPreconditions
+		}
+		if err := check(ctx.Context, operator); err != nil {
+			return err
+		}
+		avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+		if err != nil {
+			return fmt.Errorf("failed to get avs abi: %w", err)
+		}
+		data, err := avsABI.Pack(method, operator)
+		if err != nil {
+			return fmt.Errorf("failed to pack %s calldata: %w", method, err)
+		}
+		gasLimit, err := c.bundleGasLimit(ctx.Context, method, bundle, data)
+		if err != nil {
+			return err
+		}
+		bundle.Data = data
+		bundle.GasLimit = hexutil.Uint64(gasLimit)
+	}
+
+	if c.AccessList && bundle.GasPrice != nil {
+		accessList, err := c.bundleAccessList(ctx.Context, bundle)
+		if err != nil {
+			return err
+		}
+		bundle.AccessList = &accessList
+	}
+
+	// Signed bundles can't be boosted, so the fee ceiling only bounds their fees.
+	gasTip, gasFeeCap := tx.FeeParams(c.tOpts)
+	if err := c.FeeCeiling.Check(gasTip, gasFeeCap, uint64(bundle.GasLimit)); err != nil {
+		return err
+	}
+
+	if err := writeTxBundle(c.OutputFile, bundle); err != nil {
+		return err
+	}
+	c.Logger.Info("unsigned transaction bundle written",
+		"path", c.OutputFile,
+		"action", bundle.Action,
+		"from", bundle.From.Hex(),
+		"nonce", uint64(bundle.Nonce),
+		"gasLimit", uint64(bundle.GasLimit),
+	)
+	return nil
+}
+
+// bundleGasLimit returns the explicitly configured gas limit of a bundle, or
+// estimates the gas of calling method with data and applies the safety margin.
+// Registrations always have an explicit gas limit.
+func (c *Command) bundleGasLimit(ctx context.Context, method string, bundle TxBundle, data []byte) (uint64, error) {
+	if c.GasLimit != 0 {
+		if c.MaxGasLimit != 0 && c.GasLimit > c.MaxGasLimit {
+			return 0, fmt.Errorf("gas limit %d exceeds max gas limit %d", c.GasLimit, c.MaxGasLimit)
+		}
+		c.Logger.Info("gas limit set explicitly", "name", method, "gasLimit", c.GasLimit)
+		return c.GasLimit, nil
+	}
+	estimate, err := c.ethClient.EstimateGas(ctx, ethereum.CallMsg{
+		From:      bundle.From,
+		To:        &bundle.To,
+		GasFeeCap: c.tOpts.GasFeeCap,
+		GasTipCap: c.tOpts.GasTipCap,
+		GasPrice:  c.tOpts.GasPrice,
+		Data:      data,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas for %s, set it with --gas-limit: %w", method, decodeRevert(err))
+	}
+	return c.gasLimitWithMargin(method, estimate)
+}
+
+// bundleAccessList creates the access list of the bundle's call. A registration's
+// calldata is only known once it is signed, so its access list is empty.
+func (c *Command) bundleAccessList(ctx context.Context, bundle TxBundle) (ethtypes.AccessList, error) {
+	if bundle.Action == ActionRegister {
+		c.Logger.Info("registration calldata is only known once signed, preparing it with an empty access list")
+		return ethtypes.AccessList{}, nil
+	}
+	accessList, err := c.ethClient.CreateAccessList(ctx, ethereum.CallMsg{
+		From:     bundle.From,
+		To:       &bundle.To,
+		Gas:      uint64(bundle.GasLimit),
+		GasPrice: bundle.GasPrice.ToInt(),
+		Data:     bundle.Data,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create access list: %w", err)
+	}
+	return accessList, nil
+}
+
+// SignTx signs an unsigned transaction bundle with the operator's signer. With a
+// local keystore it needs no connection to an RPC endpoint.
+func (c *Command) SignTx(ctx *cli.Context) error {
+	bundle, err := readTxBundle(c.BundleFile)
+	if err != nil {
+		return err
+	}
+	c.Logger.Info("Signing transaction bundle...", "action", bundle.Action)
+	if len(bundle.SignedTx) != 0 {
+		return fmt.Errorf("bundle %s is already signed", c.BundleFile)
+	}
+	if bundle.ChainID == nil || (bundle.GasPrice == nil && (bundle.GasTipCap == nil || bundle.GasFeeCap == nil)) {
+		return fmt.Errorf("bundle %s is missing chain ID or fee params", c.BundleFile)
+	}
+	chainID := bundle.ChainID.ToInt()
+	if chainID.Cmp(&c.OperatorConfig.ChainId) != 0 {
+		return fmt.Errorf("chain ID of bundle doesn't match operator config: %s != %s",
+			chainID.String(), c.OperatorConfig.ChainId.String())
+	}
+
+	if err := c.initializeSigner(ctx.Context); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	if bundle.From != c.signer.Address() {
+		return fmt.Errorf("bundle sender %s doesn't match signing account %s",
+			bundle.From.Hex(), c.signer.Address().Hex())
+	}
+
+	if bundle.Action == ActionRegister {
+		reg := bundle.Registration
+		if reg == nil || reg.Expiry == nil {
+			return fmt.Errorf("registration bundle is missing registration digest")
+		}
+		if reg.Operator != bundle.From || reg.AVS != bundle.To {
+			return fmt.Errorf("registration digest is for operator %s and avs %s, not %s and %s",
+				reg.Operator.Hex(), reg.AVS.Hex(), bundle.From.Hex(), bundle.To.Hex())
+		}
+		if time.Unix(reg.Expiry.ToInt().Int64(), 0).Before(time.Now()) {
+			return fmt.Errorf("registration digest expired at %s, prepare a new bundle",
+				time.Unix(reg.Expiry.ToInt().Int64(), 0).UTC())
+		}
+		operatorSig, err := c.signRegistrationDigest(ctx.Context, chainID, *reg)
+		if err != nil {
+			return fmt.Errorf("failed to generate operator sig: %w", err)
+		}
+		avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+		if err != nil {
+			return fmt.Errorf("failed to get avs abi: %w", err)
+		}
+		bundle.Data, err = avsABI.Pack(actionMethods[ActionRegister], operatorSig)
+		if err != nil {
+			return fmt.Errorf("failed to pack registration calldata: %w", err)
+		}
+	}
+	if err := verifyBundleCalldata(bundle); err != nil {
+		return err
+	}
+
+	method, args, err := decodeCalldata(bundle.Data)
+	if err != nil {
+		return fmt.Errorf("failed to decode calldata: %w", err)
+	}
+	c.Logger.Info("signing transaction",
+		"to", bundle.To.Hex(),
+		"method", method,
+		"args", args,
+		"nonce", uint64(bundle.Nonce),
+		"gasLimit", uint64(bundle.GasLimit),
+		"gasTipCap", (*big.Int)(bundle.GasTipCap),
+		"gasFeeCap", (*big.Int)(bundle.GasFeeCap),
+		"gasPrice", (*big.Int)(bundle.GasPrice),
+	)
+
+	var unsignedTx *ethtypes.Transaction
+	if bundle.GasPrice != nil {
+		unsignedTx = ethtypes.NewTx(&ethtypes.LegacyTx{
+			Nonce:    uint64(bundle.Nonce),
+			GasPrice: bundle.GasPrice.ToInt(),
+			Gas:      uint64(bundle.GasLimit),
+			To:       &bundle.To,
+			Data:     bundle.Data,
+		})
+		if bundle.AccessList != nil {
+			unsignedTx = tx.NewAccessListTx(unsignedTx, chainID, *bundle.AccessList)
+		}
+	} else {
+		unsignedTx = ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     uint64(bundle.Nonce),
+			GasTipCap: bundle.GasTipCap.ToInt(),
+			GasFeeCap: bundle.GasFeeCap.ToInt(),
+			Gas:       uint64(bundle.GasLimit),
+			To:        &bundle.To,
+			Data:      bundle.Data,
+		})
+	}
+	signedTx, err := c.signer.SignTx(ctx.Context, unsignedTx, chainID)
+	if err != nil {
+		return fmt.Errorf("failed to sign tx: %w", err)
+	}
+	bundle.SignedTx, err = signedTx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode signed tx: %w", err)
+	}
+
+	if err := writeTxBundle(c.OutputFile, bundle); err != nil {
+		return err
+	}
+	c.Logger.Info("signed transaction bundle written", "path", c.OutputFile, "txHash", signedTx.Hash().Hex())
+	return nil
+}
+
+// BroadcastTx submits the signed transaction of a bundle and waits for it to be
+// mined. A pre-signed transaction cannot have its gas params boosted.
+func (c *Command) BroadcastTx(ctx *cli.Context) error {
+	bundle, err := readTxBundle(c.BundleFile)
+	if err != nil {
+		return err
+	}
+	c.Logger.Info("Broadcasting signed transaction...", "action", bundle.Action)
+	if len(bundle.SignedTx) == 0 {
+		return fmt.Errorf("bundle %s has not been signed", c.BundleFile)
+	}
+	if err := c.initializeClient(ctx); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+	if err := c.verifyContracts(ctx.Context); err != nil {
+		return err
+	}
+
+	signedTx, err := verifySignedTx(bundle, c.chainID, common.HexToAddress(c.MevCommitAVSAddress))
+	if err != nil {
+		return err
+	}
+	sender := bundle.From
+
+	switch bundle.Action {
+	case ActionRegister:
+		err = c.checkRegisterPreconditions(ctx.Context, sender)
+	case ActionRequestDeregistration:
+		err = c.checkRequestDeregPreconditions(ctx.Context, sender)
+	case ActionDeregister:
+		err = c.checkDeregPreconditions(ctx.Context, sender)
+	default:
+		err = fmt.Errorf("unknown action: %q", bundle.Action)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := c.checkBundleNonce(ctx.Context, sender, signedTx.Nonce()); err != nil {
+		return err
+	}
+
+	if c.submissionMode() == SubmissionModeBoost {
+		c.Logger.Debug("signed tx fees are fixed, waiting for it without boosting")
+	}
+	if err := c.broadcastTx(ctx.Context, signedTx); err != nil {
+		return fmt.Errorf("failed to send tx: %w", err)
+	}
+	c.Logger.Info("signed tx sent", "txHash", signedTx.Hash().Hex(), "nonce", signedTx.Nonce())
+
+	receipt, err := c.awaitReceipt(ctx.Context, signedTx)
+	if err != nil || receipt == nil {
+		return err
+	}
+
+	c.Logger.Info("Broadcast complete", "action", bundle.Action, "txHash", receipt.TxHash.Hex())
+	return nil
+}
+
+// checkBundleNonce checks that nonce is still usable by sender: either the next
+// nonce of the account, or the nonce of one of its pending transactions, which a
+// bundle prepared with --replace-nonce replaces.
+func (c *Command) checkBundleNonce(ctx context.Context, sender common.Address, nonce uint64) error {
+	latest, err := c.ethClient.NonceAt(ctx, sender, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get nonce: %w", err)
+	}
+	pending, err := c.ethClient.PendingNonceAt(ctx, sender)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	switch {
+	case nonce < latest:
+		return fmt.Errorf("tx nonce %d is stale, account nonce is %d. Please prepare a new bundle", nonce, latest)
+	case nonce > pending:
+		return fmt.Errorf("tx nonce %d is ahead of pending nonce %d. Please prepare a new bundle", nonce, pending)
+	case nonce < pending:
+		c.Logger.Warn("signed tx replaces a pending transaction, it is rejected unless priced above it",
+			"nonce", nonce, "pendingNonce", pending)
+	}
+	return nil
+}
+
+// verifySignedTx decodes the signed transaction of a bundle and checks that it is
+// signed by the bundle sender for chainID and addressed to the avs contract.
+func verifySignedTx(bundle TxBundle, chainID *big.Int, avsAddress common.Address) (*ethtypes.Transaction, error) {
+	signedTx := new(ethtypes.Transaction)
+	if err := signedTx.UnmarshalBinary(bundle.SignedTx); err != nil {
+		return nil, fmt.Errorf("failed to decode signed tx: %w", err)
+	}
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(chainID), signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover tx sender: %w", err)
+	}
+	if sender != bundle.From {
+		return nil, fmt.Errorf("tx is signed by %s, expected %s", sender.Hex(), bundle.From.Hex())
+	}
+	if signedTx.To() == nil || *signedTx.To() != avsAddress {
+		return nil, fmt.Errorf("tx is not addressed to the avs contract %s", avsAddress.Hex())
+	}
+	if expected := bundleTxType(bundle); signedTx.Type() != expected {
+		return nil, fmt.Errorf("tx is of type %d, expected %d", signedTx.Type(), expected)
+	}
+	if bundle.AccessList != nil && !slices.EqualFunc(signedTx.AccessList(), *bundle.AccessList, accessTuplesEqual) {
+		return nil, fmt.Errorf("tx access list doesn't match the bundle's")
+	}
+	return signedTx, nil
+}
+
+// bundleTxType returns the type of the tx a bundle is signed as.
+func bundleTxType(bundle TxBundle) uint8 {
+	switch {
+	case bundle.GasPrice == nil:
+		return ethtypes.DynamicFeeTxType
+	case bundle.AccessList != nil:
+		return ethtypes.AccessListTxType
+	default:
+		return ethtypes.LegacyTxType
+	}
+}
+
+func accessTuplesEqual(a, b ethtypes.AccessTuple) bool {
+	return a.Address == b.Address && slices.Equal(a.StorageKeys, b.StorageKeys)
+}
+
+// verifyBundleCalldata checks that the calldata of a bundle calls the AVS method of
+// its action on behalf of the bundle sender.
+func verifyBundleCalldata(bundle TxBundle) error {
+	expected, ok := actionMethods[bundle.Action]
+	if !ok {
+		return fmt.Errorf("unknown action: %q", bundle.Action)
+	}
+	avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+	if err != nil {
+		return fmt.Errorf("failed to get avs abi: %w", err)
+	}
+	if len(bundle.Data) < 4 {
+		return fmt.Errorf("bundle calldata too short: %d bytes", len(bundle.Data))
+	}
+	method, err := avsABI.MethodById(bundle.Data[:4])
+	if err != nil {
+		return fmt.Errorf("failed to decode bundle calldata: %w", err)
+	}
+	if method.Name != expected {
+		return fmt.Errorf("bundle calldata calls %s, expected %s", method.Name, expected)
+	}
+	if bundle.Action == ActionRegister {
+		return nil
+	}
+	args, err := method.Inputs.Unpack(bundle.Data[4:])
+	if err != nil {
+		return fmt.Errorf("failed to unpack bundle calldata: %w", err)
+	}
+	if operator, ok := args[0].(common.Address); !ok || operator != bundle.From {
+		return fmt.Errorf("bundle calldata targets operator %v, expected %s", args[0], bundle.From.Hex())
+	}
+	return nil
+}
+
+func readTxBundle(path string) (TxBundle, error) {
+	bz, err := os.ReadFile(path)
+	if err != nil {
+		return TxBundle{}, fmt.Errorf("read tx bundle: %w", err)
+	}
+	var bundle TxBundle
+	if err := json.Unmarshal(bz, &bundle); err != nil {
+		return TxBundle{}, fmt.Errorf("unmarshal tx bundle: %w", err)
+	}
+	return bundle, nil
+}
+
+func writeTxBundle(path string, bundle TxBundle) error {
+	bz, err := json.MarshalIndent(bundle, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshal tx bundle: %w", err)
+	}
+	if err := os.WriteFile(path, append(bz, '\n'), 0600); err != nil {
+		return fmt.Errorf("write tx bundle: %w", err)
+	}
+	return nil
+}
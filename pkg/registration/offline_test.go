@@ -0,0 +1,384 @@
+package registration
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"eigen-operator-cli/pkg/multiclient"
+	"flag"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+	"github.com/urfave/cli/v2"
+	"gotest.tools/assert"
+)
+
+const keystorePassword = "password"
+
+var (
+	offlineChainID = big.NewInt(17000)
+	offlineAVS     = common.HexToAddress("0xEDEDB8ed37A43Fd399108A44646B85b780D85DD4")
+	offlineAVSDir  = common.HexToAddress("0x055733000064333CaDDbC92763c58BF0192fFeBf")
+)
+
+// newOfflineCommand returns a command signing with a fresh keystore account,
+// writing its output to a temporary file.
+func newOfflineCommand(t *testing.T) *Command {
+	t.Helper()
+	dir := t.TempDir()
+	ks := keystore.NewKeyStore(dir, keystore.LightScryptN, keystore.LightScryptP)
+	account, err := ks.NewAccount(keystorePassword)
+	assert.NilError(t, err)
+
+	operConfig := &eigenclitypes.OperatorConfig{PrivateKeyStorePath: account.URL.Path}
+	operConfig.Operator.Address = account.Address.Hex()
+	operConfig.ChainId = *offlineChainID
+	return &Command{
+		OperatorConfig:      operConfig,
+		KeystorePassword:    keystorePassword,
+		MevCommitAVSAddress: offlineAVS.Hex(),
+		OutputFile:          filepath.Join(t.TempDir(), "signed.json"),
+		Logger:              slog.Default(),
+	}
+}
+
+// unsignedRegisterBundle returns a registration bundle for operator, as PrepareTx writes it.
+func unsignedRegisterBundle(t *testing.T, operator common.Address) TxBundle {
+	t.Helper()
+	reg := RegistrationDigest{
+		AVSDirectory: offlineAVSDir,
+		AVS:          offlineAVS,
+		Operator:     operator,
+		Salt:         crypto.Keccak256Hash(operator.Bytes(), []byte(offlineAVS.Hex())),
+		Expiry:       (*hexutil.Big)(big.NewInt(time.Now().Add(time.Hour).Unix())),
+	}
+	digest, err := calculateRegistrationDigest(offlineChainID, reg)
+	assert.NilError(t, err)
+	reg.Digest = digest
+	return TxBundle{
+		Action:       ActionRegister,
+		ChainID:      (*hexutil.Big)(offlineChainID),
+		From:         operator,
+		To:           offlineAVS,
+		Nonce:        7,
+		GasLimit:     250000,
+		GasTipCap:    (*hexutil.Big)(big.NewInt(1e9)),
+		GasFeeCap:    (*hexutil.Big)(big.NewInt(3e10)),
+		Registration: &reg,
+	}
+}
+
+func cliContext() *cli.Context {
+	ctx := cli.NewContext(cli.NewApp(), flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	ctx.Context = context.Background()
+	return ctx
+}
+
+func TestOfflineRegisterRoundTrip(t *testing.T) {
+	c := newOfflineCommand(t)
+	operator := common.HexToAddress(c.OperatorConfig.Operator.Address)
+	unsigned := unsignedRegisterBundle(t, operator)
+	// The digest read from the AVS directory is signed as is, even if its EIP-712
+	// domain isn't the one the CLI would rebuild it with.
+	unsigned.Registration.Digest = crypto.Keccak256Hash([]byte("another domain"), unsigned.Registration.Digest.Bytes())
+	c.BundleFile = filepath.Join(t.TempDir(), "unsigned.json")
+	assert.NilError(t, writeTxBundle(c.BundleFile, unsigned))
+
+	assert.NilError(t, c.SignTx(cliContext()))
+
+	signed, err := readTxBundle(c.OutputFile)
+	assert.NilError(t, err)
+	assert.Equal(t, unsigned.Action, signed.Action)
+	assert.Equal(t, unsigned.From, signed.From)
+	assert.Equal(t, unsigned.Nonce, signed.Nonce)
+	assert.Equal(t, unsigned.GasLimit, signed.GasLimit)
+	assert.Equal(t, unsigned.Registration.Digest, signed.Registration.Digest)
+	assert.Equal(t, unsigned.Registration.Expiry.String(), signed.Registration.Expiry.String())
+
+	signedTx, err := verifySignedTx(signed, offlineChainID, offlineAVS)
+	assert.NilError(t, err)
+	assert.Equal(t, uint64(unsigned.Nonce), signedTx.Nonce())
+	assert.Equal(t, uint64(unsigned.GasLimit), signedTx.Gas())
+	assert.Equal(t, unsigned.GasFeeCap.ToInt().String(), signedTx.GasFeeCap().String())
+
+	// The registration signature in the calldata must recover to the operator.
+	avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+	assert.NilError(t, err)
+	args, err := avsABI.Methods[actionMethods[ActionRegister]].Inputs.Unpack(signedTx.Data()[4:])
+	assert.NilError(t, err)
+	operatorSig := args[0].(struct {
+		Signature []byte   `json:"signature"`
+		Salt      [32]byte `json:"salt"`
+		Expiry    *big.Int `json:"expiry"`
+	})
+	sig := append([]byte{}, operatorSig.Signature...)
+	sig[crypto.RecoveryIDOffset] -= 27
+	pubkey, err := crypto.SigToPub(unsigned.Registration.Digest.Bytes(), sig)
+	assert.NilError(t, err)
+	assert.Equal(t, operator, crypto.PubkeyToAddress(*pubkey))
+}
+
+func TestSignTxTypes(t *testing.T) {
+	accessList := ethtypes.AccessList{{
+		Address:     offlineAVSDir,
+		StorageKeys: []common.Hash{common.HexToHash("0x01")},
+	}}
+	tests := []struct {
+		name         string
+		gasPrice     *big.Int
+		accessList   *ethtypes.AccessList
+		expectedType uint8
+	}{
+		{
+			name:         "dynamic fee",
+			expectedType: ethtypes.DynamicFeeTxType,
+		},
+		{
+			name:         "legacy",
+			gasPrice:     big.NewInt(2e10),
+			expectedType: ethtypes.LegacyTxType,
+		},
+		{
+			name:         "access list",
+			gasPrice:     big.NewInt(2e10),
+			accessList:   &accessList,
+			expectedType: ethtypes.AccessListTxType,
+		},
+		{
+			name:         "empty access list",
+			gasPrice:     big.NewInt(2e10),
+			accessList:   &ethtypes.AccessList{},
+			expectedType: ethtypes.AccessListTxType,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newOfflineCommand(t)
+			unsigned := unsignedRegisterBundle(t, common.HexToAddress(c.OperatorConfig.Operator.Address))
+			if tc.gasPrice != nil {
+				unsigned.GasTipCap, unsigned.GasFeeCap = nil, nil
+				unsigned.GasPrice = (*hexutil.Big)(tc.gasPrice)
+			}
+			unsigned.AccessList = tc.accessList
+			c.BundleFile = filepath.Join(t.TempDir(), "unsigned.json")
+			assert.NilError(t, writeTxBundle(c.BundleFile, unsigned))
+
+			assert.NilError(t, c.SignTx(cliContext()))
+
+			signed, err := readTxBundle(c.OutputFile)
+			assert.NilError(t, err)
+			signedTx, err := verifySignedTx(signed, offlineChainID, offlineAVS)
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedType, signedTx.Type())
+			if tc.accessList != nil {
+				assert.DeepEqual(t, *tc.accessList, signedTx.AccessList())
+			}
+
+			// The signed tx must be of the type the bundle was prepared for.
+			switch {
+			case tc.gasPrice == nil:
+				signed.GasPrice = (*hexutil.Big)(big.NewInt(2e10))
+			case tc.accessList == nil:
+				signed.AccessList = &accessList
+			default:
+				signed.AccessList = nil
+			}
+			_, err = verifySignedTx(signed, offlineChainID, offlineAVS)
+			assert.ErrorContains(t, err, "tx is of type")
+		})
+	}
+}
+
+func TestPrepareRegisterRequiresGasLimit(t *testing.T) {
+	c := newOfflineCommand(t)
+	c.Action = ActionRegister
+	assert.Error(t, c.PrepareTx(cliContext()),
+		"a registration can't be estimated before it is signed, set its gas limit with --gas-limit")
+}
+
+func TestSignTxRejectsBundle(t *testing.T) {
+	tests := []struct {
+		name          string
+		tamper        func(*TxBundle)
+		expectedError string
+	}{
+		{
+			name: "chain id mismatch",
+			tamper: func(b *TxBundle) {
+				b.ChainID = (*hexutil.Big)(big.NewInt(1))
+			},
+			expectedError: "chain ID of bundle doesn't match operator config: 1 != 17000",
+		},
+		{
+			name: "digest for another operator",
+			tamper: func(b *TxBundle) {
+				b.Registration.Operator = common.HexToAddress("0x03")
+			},
+			expectedError: "registration digest is for operator",
+		},
+		{
+			name: "sender is not the signer",
+			tamper: func(b *TxBundle) {
+				b.From = common.HexToAddress("0x04")
+			},
+			expectedError: "bundle sender 0x0000000000000000000000000000000000000004 doesn't match signing account",
+		},
+		{
+			name: "already signed",
+			tamper: func(b *TxBundle) {
+				b.SignedTx = hexutil.Bytes{0x01}
+			},
+			expectedError: "is already signed",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newOfflineCommand(t)
+			bundle := unsignedRegisterBundle(t, common.HexToAddress(c.OperatorConfig.Operator.Address))
+			tc.tamper(&bundle)
+			c.BundleFile = filepath.Join(t.TempDir(), "unsigned.json")
+			assert.NilError(t, writeTxBundle(c.BundleFile, bundle))
+
+			assert.ErrorContains(t, c.SignTx(cliContext()), tc.expectedError)
+		})
+	}
+}
+
+func TestVerifySignedTx(t *testing.T) {
+	operatorKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	otherKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	operator := crypto.PubkeyToAddress(operatorKey.PublicKey)
+
+	signTx := func(t *testing.T, to common.Address, chainID *big.Int, key *ecdsa.PrivateKey) hexutil.Bytes {
+		t.Helper()
+		tx := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     7,
+			GasTipCap: big.NewInt(1e9),
+			GasFeeCap: big.NewInt(3e10),
+			Gas:       250000,
+			To:        &to,
+		})
+		signed, err := ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(chainID), key)
+		assert.NilError(t, err)
+		bz, err := signed.MarshalBinary()
+		assert.NilError(t, err)
+		return bz
+	}
+
+	tests := []struct {
+		name          string
+		signedTx      hexutil.Bytes
+		expectedError string
+	}{
+		{
+			name:     "signed by operator",
+			signedTx: signTx(t, offlineAVS, offlineChainID, operatorKey),
+		},
+		{
+			name:          "signed by another account",
+			signedTx:      signTx(t, offlineAVS, offlineChainID, otherKey),
+			expectedError: "tx is signed by " + crypto.PubkeyToAddress(otherKey.PublicKey).Hex(),
+		},
+		{
+			name:          "signed for another chain",
+			signedTx:      signTx(t, offlineAVS, big.NewInt(1), operatorKey),
+			expectedError: "failed to recover tx sender",
+		},
+		{
+			name:          "not addressed to the avs",
+			signedTx:      signTx(t, offlineAVSDir, offlineChainID, operatorKey),
+			expectedError: "tx is not addressed to the avs contract",
+		},
+		{
+			name:          "malformed",
+			signedTx:      hexutil.Bytes{0x02, 0x01},
+			expectedError: "failed to decode signed tx",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			bundle := TxBundle{From: operator, SignedTx: tc.signedTx}
+			signedTx, err := verifySignedTx(bundle, offlineChainID, offlineAVS)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, offlineAVS, *signedTx.To())
+		})
+	}
+}
+
+// accountNonceStandIn serves the latest and pending nonces of an account over JSON-RPC.
+type accountNonceStandIn struct {
+	latest  uint64
+	pending uint64
+}
+
+func (e accountNonceStandIn) GetTransactionCount(account common.Address, block string) hexutil.Uint64 {
+	if block == "pending" {
+		return hexutil.Uint64(e.pending)
+	}
+	return hexutil.Uint64(e.latest)
+}
+
+func TestCheckBundleNonce(t *testing.T) {
+	tests := []struct {
+		name          string
+		nonce         uint64
+		expectedError string
+	}{
+		{
+			name:  "next nonce",
+			nonce: 7,
+		},
+		{
+			name:  "replaces pending tx",
+			nonce: 5,
+		},
+		{
+			name:          "error, stale",
+			nonce:         4,
+			expectedError: "tx nonce 4 is stale, account nonce is 5",
+		},
+		{
+			name:          "error, ahead of pending",
+			nonce:         8,
+			expectedError: "tx nonce 8 is ahead of pending nonce 7",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			assert.NilError(t, server.RegisterName("eth", accountNonceStandIn{latest: 5, pending: 7}))
+			t.Cleanup(server.Stop)
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+
+			ethClient, err := multiclient.Dial(context.Background(), []string{httpServer.URL}, time.Second, slog.Default())
+			assert.NilError(t, err)
+			c := &Command{ethClient: ethClient, Logger: slog.Default()}
+
+			err = c.checkBundleNonce(context.Background(), offlineAVS, tc.nonce)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
@@ -0,0 +1,105 @@
+package registration
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// blsPubkeyLength is the length in bytes of a compressed BLS12-381 validator pubkey.
+const blsPubkeyLength = 48
+
+// depositData is the subset of an entry of a beacon deposit data file, as
+// written by the staking deposit CLI, that identifies the validator.
+type depositData struct {
+	Pubkey string `json:"pubkey"`
+}
+
+// readValidatorPubkeys collects validator BLS pubkeys from a list of hex strings,
+// a file with one hex pubkey per line and a beacon deposit data JSON file. Empty
+// sources are skipped. Malformed and duplicate pubkeys are rejected.
+func readValidatorPubkeys(list []string, file, depositDataFile string) ([][]byte, error) {
+	var encoded []string
+	for _, s := range list {
+		encoded = append(encoded, strings.Split(s, ",")...)
+	}
+
+	if file != "" {
+		f, err := os.Open(file)
+		if err != nil {
+			return nil, fmt.Errorf("open validator pubkeys file: %w", err)
+		}
+		defer f.Close()
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			encoded = append(encoded, line)
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("read validator pubkeys file: %w", err)
+		}
+	}
+
+	if depositDataFile != "" {
+		bz, err := os.ReadFile(depositDataFile)
+		if err != nil {
+			return nil, fmt.Errorf("read deposit data file: %w", err)
+		}
+		var deposits []depositData
+		if err := json.Unmarshal(bz, &deposits); err != nil {
+			return nil, fmt.Errorf("unmarshal deposit data file: %w", err)
+		}
+		for i, d := range deposits {
+			if d.Pubkey == "" {
+				return nil, fmt.Errorf("deposit data entry %d has no pubkey", i)
+			}
+			encoded = append(encoded, d.Pubkey)
+		}
+	}
+
+	seen := make(map[string]bool)
+	var pubkeys [][]byte
+	for _, s := range encoded {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		pubkey, err := parseValidatorPubkey(s)
+		if err != nil {
+			return nil, err
+		}
+		key := hexutil.Encode(pubkey)
+		if seen[key] {
+			return nil, fmt.Errorf("duplicate validator pubkey: %s", key)
+		}
+		seen[key] = true
+		pubkeys = append(pubkeys, pubkey)
+	}
+	if len(pubkeys) == 0 {
+		return nil, fmt.Errorf("no validator pubkeys provided")
+	}
+	return pubkeys, nil
+}
+
+// parseValidatorPubkey decodes a hex BLS pubkey, with or without 0x prefix.
+func parseValidatorPubkey(s string) ([]byte, error) {
+	if !strings.HasPrefix(s, "0x") && !strings.HasPrefix(s, "0X") {
+		s = "0x" + s
+	}
+	pubkey, err := hexutil.Decode(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid validator pubkey %q: %w", s, err)
+	}
+	if len(pubkey) != blsPubkeyLength {
+		return nil, fmt.Errorf("invalid validator pubkey %q: expected %d bytes, got %d",
+			s, blsPubkeyLength, len(pubkey))
+	}
+	return pubkey, nil
+}
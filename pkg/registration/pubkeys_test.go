@@ -0,0 +1,73 @@
+package registration
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestReadValidatorPubkeys(t *testing.T) {
+	key1 := "0x" + strings.Repeat("aa", blsPubkeyLength)
+	key2 := strings.Repeat("bb", blsPubkeyLength)
+	key3 := "0x" + strings.Repeat("cc", blsPubkeyLength)
+
+	dir := t.TempDir()
+	pubkeysFile := filepath.Join(dir, "pubkeys.txt")
+	assert.NilError(t, os.WriteFile(pubkeysFile, []byte("# validators\n"+key2+"\n\n"), 0600))
+	depositDataFile := filepath.Join(dir, "deposit_data.json")
+	assert.NilError(t, os.WriteFile(depositDataFile, []byte(`[{"pubkey":"`+key3[2:]+`"}]`), 0600))
+
+	tests := []struct {
+		name            string
+		list            []string
+		file            string
+		depositDataFile string
+		expectedCount   int
+		expectedError   string
+	}{
+		{
+			name:          "comma-separated list",
+			list:          []string{key1 + "," + key2},
+			expectedCount: 2,
+		},
+		{
+			name:            "all sources",
+			list:            []string{key1},
+			file:            pubkeysFile,
+			depositDataFile: depositDataFile,
+			expectedCount:   3,
+		},
+		{
+			name:          "error, duplicate",
+			list:          []string{key1, key1},
+			expectedError: "duplicate validator pubkey",
+		},
+		{
+			name:          "error, wrong length",
+			list:          []string{"0xaabb"},
+			expectedError: "expected 48 bytes, got 2",
+		},
+		{
+			name:          "error, none provided",
+			expectedError: "no validator pubkeys provided",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pubkeys, err := readValidatorPubkeys(tt.list, tt.file, tt.depositDataFile)
+			if tt.expectedError != "" {
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, len(pubkeys), tt.expectedCount)
+			for _, pubkey := range pubkeys {
+				assert.Equal(t, len(pubkey), blsPubkeyLength)
+			}
+		})
+	}
+}
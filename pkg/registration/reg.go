@@ -2,17 +2,19 @@ package registration
 
 import (
 	"context"
-	"eigen-operator-cli/pkg/tx"
+	"eigen-operator-cli/pkg/signer"
 	"fmt"
 	"math/big"
 	"time"
 
 	avsdir "github.com/Layr-Labs/eigensdk-go/contracts/bindings/AVSDirectory"
-	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
 	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
 	"github.com/urfave/cli/v2"
 )
@@ -24,130 +26,200 @@ func (c *Command) RegisterOperator(ctx *cli.Context) error {
 		return fmt.Errorf("failed to initialize: %w", err)
 	}
 
-	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(
-		&bind.CallOpts{Context: ctx.Context}, c.account.Address)
-	if err != nil {
-		return fmt.Errorf("failed to get operator reg info: %w", err)
-	}
-	if operatorRegInfo.Exists {
-		return fmt.Errorf("signing operator already registered")
+	if err := c.checkRegisterPreconditions(ctx.Context, c.signer.Address()); err != nil {
+		return err
 	}
 
-	isEigenOperator, err := c.dmC.IsOperator(&bind.CallOpts{}, c.account.Address)
-	if err != nil {
-		return fmt.Errorf("failed to check if operator is registered with eigen core: %w", err)
-	}
-	if !isEigenOperator {
-		return fmt.Errorf("signer is not a registered operator with eigen core")
-	}
-
-	operatorSig, err := c.generateOperatorSig()
+	operatorSig, err := c.generateOperatorSig(ctx.Context)
 	if err != nil {
 		return fmt.Errorf("failed to generate operator sig: %w", err)
 	}
 
-	submitTx := func(
+	buildTx := func(
 		ctx context.Context,
 		opts *bind.TransactOpts,
 	) (*ethtypes.Transaction, error) {
-		tx, err := c.avsT.RegisterOperator(c.tOpts, operatorSig)
+		tx, err := c.avsT.RegisterOperator(opts, operatorSig)
 		if err != nil {
 			return nil, fmt.Errorf("failed to register operator: %w", err)
 		}
-		c.Logger.Info("RegisterOperator tx sent", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
 		return tx, nil
 	}
 
-	var receipt *ethtypes.Receipt
-	if c.BoostGasParams {
-		receipt, err = tx.WaitMinedWithRetry(ctx.Context, c.tOpts, submitTx, c.ethClient, c.Logger)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
-	} else {
-		tx, err := submitTx(ctx.Context, c.tOpts)
-		if err != nil {
-			return fmt.Errorf("failed to submit tx: %w", err)
-		}
-		c.Logger.Info("waiting for tx to be mined", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
-		receipt, err = bind.WaitMined(ctx.Context, c.ethClient, tx)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
+	if c.DryRun {
+		return c.dryRun(ctx.Context, "RegisterOperator", buildTx)
 	}
-	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
-		errRevertReason := c.getRevertReason(ctx.Context, receipt)
-		return fmt.Errorf("receipt status unsuccessful: %d, %w", receipt.Status, errRevertReason)
+
+	receipt, err := c.sendTx(ctx.Context, "RegisterOperator", buildTx)
+	if err != nil || receipt == nil {
+		return err
 	}
 
 	c.Logger.Info("Registration complete", "txHash", receipt.TxHash.Hex())
 	return nil
 }
 
-func (c *Command) generateOperatorSig() (avs.ISignatureUtilsSignatureWithSaltAndExpiry, error) {
+// checkRegisterPreconditions verifies that operator can register with the AVS.
+func (c *Command) checkRegisterPreconditions(ctx context.Context, operator common.Address) error {
+	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(
+		&bind.CallOpts{Context: ctx}, operator)
+	if err != nil {
+		return fmt.Errorf("failed to get operator reg info: %w", err)
+	}
+	if operatorRegInfo.Exists {
+		return fmt.Errorf("signing operator already registered")
+	}
 
-	avsDirAddr, err := c.avsC.AvsDirectory(&bind.CallOpts{})
+	isEigenOperator, err := c.dmC.IsOperator(&bind.CallOpts{Context: ctx}, operator)
 	if err != nil {
-		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("failed to get avs dir address: %w", err)
+		return fmt.Errorf("failed to check if operator is registered with eigen core: %w", err)
+	}
+	if !isEigenOperator {
+		return fmt.Errorf("signer is not a registered operator with eigen core")
+	}
+	return nil
+}
+
+// RegistrationDigest holds the parameters of an operator's AVS registration
+// signature together with the digest the operator must sign.
+type RegistrationDigest struct {
+	AVSDirectory common.Address `json:"avsDirectory"`
+	AVS          common.Address `json:"avs"`
+	Operator     common.Address `json:"operator"`
+	Salt         common.Hash    `json:"salt"`
+	Expiry       *hexutil.Big   `json:"expiry"`
+	Digest       common.Hash    `json:"digest"`
+}
+
+func (c *Command) generateOperatorSig(ctx context.Context) (avs.ISignatureUtilsSignatureWithSaltAndExpiry, error) {
+	regDigest, err := c.operatorRegistrationDigest(ctx, c.signer.Address())
+	if err != nil {
+		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, err
+	}
+	return c.signRegistrationDigest(ctx, c.chainID, regDigest)
+}
+
+// operatorRegistrationDigest queries the AVS directory for the digest the operator
+// must sign to register with the AVS.
+func (c *Command) operatorRegistrationDigest(ctx context.Context, operatorAddr common.Address) (RegistrationDigest, error) {
+	avsDirAddr, err := c.avsC.AvsDirectory(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return RegistrationDigest{}, fmt.Errorf("failed to get avs dir address: %w", err)
 	}
 
 	avsDir, err := avsdir.NewContractAVSDirectoryCaller(avsDirAddr, c.ethClient)
 	if err != nil {
-		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("failed to create avs dir: %w", err)
+		return RegistrationDigest{}, fmt.Errorf("failed to create avs dir: %w", err)
 	}
 	if avsDir == nil {
-		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("avs dir is nil")
+		return RegistrationDigest{}, fmt.Errorf("avs dir is nil")
 	}
 
-	operatorAddr := c.account.Address
 	salt := crypto.Keccak256Hash(operatorAddr.Bytes(), []byte(c.MevCommitAVSAddress))
-	expiry := big.NewInt(time.Now().Add(time.Hour).Unix())
-	digestHash, err := avsDir.CalculateOperatorAVSRegistrationDigestHash(&bind.CallOpts{},
+	expiry := big.NewInt(time.Now().Add(c.signatureExpiry()).Unix())
+	digestHash, err := avsDir.CalculateOperatorAVSRegistrationDigestHash(&bind.CallOpts{Context: ctx},
 		operatorAddr,
 		common.HexToAddress(c.MevCommitAVSAddress),
 		salt,
 		expiry)
 	if err != nil {
-		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("failed to calculate digest hash: %w", err)
+		return RegistrationDigest{}, fmt.Errorf("failed to calculate digest hash: %w", err)
 	}
 
-	hashSig, err := c.keystore.SignHash(c.account, digestHash[:])
-	if err != nil {
-		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("failed to sign digest hash: %w", err)
+	return RegistrationDigest{
+		AVSDirectory: avsDirAddr,
+		AVS:          common.HexToAddress(c.MevCommitAVSAddress),
+		Operator:     operatorAddr,
+		Salt:         salt,
+		Expiry:       (*hexutil.Big)(expiry),
+		Digest:       digestHash,
+	}, nil
+}
+
+func (c *Command) signatureExpiry() time.Duration {
+	if c.SignatureExpiry == 0 {
+		return defaultSignatureExpiry
 	}
+	return c.SignatureExpiry
+}
 
-	// V is 0 or 1 from SignHash, but needs to be 27 or 28. See https://github.com/ethereum/go-ethereum/issues/19751
-	if hashSig[64] < 27 {
-		hashSig[64] += 27
+// signRegistrationDigest has the operator's signer sign the registration digest.
+// Signers that can sign a hash sign the digest computed by the AVS directory as
+// is. Others sign EIP-712 typed data, which is rebuilt from the digest's
+// parameters and must hash to the same digest.
+func (c *Command) signRegistrationDigest(ctx context.Context, chainID *big.Int, regDigest RegistrationDigest) (
+	avs.ISignatureUtilsSignatureWithSaltAndExpiry, error) {
+
+	var hashSig []byte
+	var err error
+	if hashSigner, ok := c.signer.(signer.HashSigner); ok {
+		hashSig, err = hashSigner.SignHash(ctx, regDigest.Digest)
+	} else {
+		hashSig, err = c.signTypedRegistration(ctx, chainID, regDigest)
+	}
+	if err != nil {
+		return avs.ISignatureUtilsSignatureWithSaltAndExpiry{}, fmt.Errorf("failed to sign digest hash: %w", err)
 	}
 
 	return avs.ISignatureUtilsSignatureWithSaltAndExpiry{
 		Signature: hashSig,
-		Salt:      salt,
-		Expiry:    expiry,
+		Salt:      regDigest.Salt,
+		Expiry:    regDigest.Expiry.ToInt(),
 	}, nil
 }
 
-func (c *Command) getRevertReason(ctx context.Context, receipt *ethtypes.Receipt) error {
-	tx, _, err := c.ethClient.TransactionByHash(ctx, receipt.TxHash)
+// signTypedRegistration signs the registration as EIP-712 typed data, after
+// checking the typed data hashes to the digest from the AVS directory.
+func (c *Command) signTypedRegistration(ctx context.Context, chainID *big.Int, regDigest RegistrationDigest) ([]byte, error) {
+	digest, err := calculateRegistrationDigest(chainID, regDigest)
 	if err != nil {
-		return fmt.Errorf("failed to get transaction: %w", err)
+		return nil, err
 	}
-
-	from, err := c.ethClient.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
-	if err != nil {
-		return fmt.Errorf("failed to get transaction sender: %w", err)
+	if digest != regDigest.Digest {
+		return nil, fmt.Errorf("registration digest %s doesn't match computed digest %s",
+			regDigest.Digest.Hex(), digest.Hex())
 	}
+	return c.signer.SignTypedData(ctx, registrationTypedData(chainID, regDigest))
+}
 
-	msg := ethereum.CallMsg{
-		From:     from,
-		To:       tx.To(),
-		Gas:      tx.Gas(),
-		GasPrice: tx.GasPrice(),
-		Value:    tx.Value(),
-		Data:     tx.Data(),
+// registrationTypedData returns the EIP-712 payload whose hash the AVS directory
+// expects the operator to sign.
+func registrationTypedData(chainID *big.Int, regDigest RegistrationDigest) apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"OperatorAVSRegistration": {
+				{Name: "operator", Type: "address"},
+				{Name: "avs", Type: "address"},
+				{Name: "salt", Type: "bytes32"},
+				{Name: "expiry", Type: "uint256"},
+			},
+		},
+		PrimaryType: "OperatorAVSRegistration",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "EigenLayer",
+			ChainId:           (*math.HexOrDecimal256)(chainID),
+			VerifyingContract: regDigest.AVSDirectory.Hex(),
+		},
+		Message: apitypes.TypedDataMessage{
+			"operator": regDigest.Operator.Hex(),
+			"avs":      regDigest.AVS.Hex(),
+			"salt":     regDigest.Salt.Hex(),
+			"expiry":   regDigest.Expiry.ToInt().String(),
+		},
 	}
+}
 
-	_, err = c.ethClient.CallContract(ctx, msg, receipt.BlockNumber)
-	return err
+// calculateRegistrationDigest computes the digest that the AVS directory expects
+// the operator to sign, without querying the chain.
+func calculateRegistrationDigest(chainID *big.Int, regDigest RegistrationDigest) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(registrationTypedData(chainID, regDigest))
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash registration typed data: %w", err)
+	}
+	return common.BytesToHash(hash), nil
 }
@@ -0,0 +1,72 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/signer"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/assert"
+)
+
+// typedDataSigner signs only EIP-712 typed data, as a remote signer does.
+type typedDataSigner struct {
+	signer.Signer
+}
+
+func TestSignRegistrationDigest(t *testing.T) {
+	tests := []struct {
+		name          string
+		typedDataOnly bool
+		// otherDomain stands for an AVS directory whose EIP-712 domain differs
+		// from the one the digest is rebuilt with.
+		otherDomain   bool
+		expectedError string
+	}{
+		{
+			name: "keystore signs digest",
+		},
+		{
+			name:        "keystore signs digest of another domain",
+			otherDomain: true,
+		},
+		{
+			name:          "typed data",
+			typedDataOnly: true,
+		},
+		{
+			name:          "error, typed data of another domain",
+			typedDataOnly: true,
+			otherDomain:   true,
+			expectedError: "doesn't match computed digest",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newOfflineCommand(t)
+			assert.NilError(t, c.initializeSigner(context.Background()))
+			operator := c.signer.Address()
+			if tc.typedDataOnly {
+				c.signer = typedDataSigner{c.signer}
+			}
+			reg := *unsignedRegisterBundle(t, operator).Registration
+			if tc.otherDomain {
+				reg.Digest = crypto.Keccak256Hash([]byte("another domain"), reg.Digest.Bytes())
+			}
+
+			sig, err := c.signRegistrationDigest(context.Background(), offlineChainID, reg)
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, reg.Salt, common.Hash(sig.Salt))
+			recoverable := append([]byte{}, sig.Signature...)
+			recoverable[crypto.RecoveryIDOffset] -= 27
+			pubkey, err := crypto.SigToPub(reg.Digest.Bytes(), recoverable)
+			assert.NilError(t, err)
+			assert.Equal(t, operator, crypto.PubkeyToAddress(*pubkey))
+		})
+	}
+}
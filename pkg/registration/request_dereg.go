@@ -2,10 +2,10 @@ package registration
 
 import (
 	"context"
-	"eigen-operator-cli/pkg/tx"
 	"fmt"
 
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/urfave/cli/v2"
 )
@@ -17,51 +17,46 @@ func (c *Command) RequestOperatorDeregistration(ctx *cli.Context) error {
 		return fmt.Errorf("failed to initialize: %w", err)
 	}
 
-	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(
-		&bind.CallOpts{Context: ctx.Context}, c.account.Address)
-	if err != nil {
-		return fmt.Errorf("failed to get operator reg info: %w", err)
-	}
-	if !operatorRegInfo.Exists {
-		return fmt.Errorf("signing operator must be registered")
-	}
-	if operatorRegInfo.DeregRequestHeight.Exists {
-		return fmt.Errorf("signing operator already requested deregistration")
+	if err := c.checkRequestDeregPreconditions(ctx.Context, c.signer.Address()); err != nil {
+		return err
 	}
 
-	submitTx := func(
+	buildTx := func(
 		ctx context.Context,
 		opts *bind.TransactOpts,
 	) (*ethtypes.Transaction, error) {
-		tx, err := c.avsT.RequestOperatorDeregistration(c.tOpts, c.account.Address)
+		tx, err := c.avsT.RequestOperatorDeregistration(opts, c.signer.Address())
 		if err != nil {
 			return nil, fmt.Errorf("failed to request operator deregistration: %w", err)
 		}
-		c.Logger.Info("RequestOperatorDeregistration tx sent", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
 		return tx, nil
 	}
 
-	var receipt *ethtypes.Receipt
-	if c.BoostGasParams {
-		receipt, err = tx.WaitMinedWithRetry(ctx.Context, c.tOpts, submitTx, c.ethClient, c.Logger)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
-	} else {
-		tx, err := submitTx(ctx.Context, c.tOpts)
-		if err != nil {
-			return fmt.Errorf("failed to submit tx: %w", err)
-		}
-		c.Logger.Info("waiting for tx to be mined", "txHash", tx.Hash().Hex(), "nonce", tx.Nonce())
-		receipt, err = bind.WaitMined(ctx.Context, c.ethClient, tx)
-		if err != nil {
-			return fmt.Errorf("failed to wait for tx to be mined: %w", err)
-		}
+	if c.DryRun {
+		return c.dryRun(ctx.Context, "RequestOperatorDeregistration", buildTx)
 	}
-	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
-		return fmt.Errorf("receipt status unsuccessful: %d", receipt.Status)
+
+	receipt, err := c.sendTx(ctx.Context, "RequestOperatorDeregistration", buildTx)
+	if err != nil || receipt == nil {
+		return err
 	}
 
 	c.Logger.Info("RequestOperatorDeregistration complete", "txHash", receipt.TxHash.Hex())
 	return nil
 }
+
+// checkRequestDeregPreconditions verifies that operator can request deregistration from the AVS.
+func (c *Command) checkRequestDeregPreconditions(ctx context.Context, operator common.Address) error {
+	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(
+		&bind.CallOpts{Context: ctx}, operator)
+	if err != nil {
+		return fmt.Errorf("failed to get operator reg info: %w", err)
+	}
+	if !operatorRegInfo.Exists {
+		return fmt.Errorf("signing operator must be registered")
+	}
+	if operatorRegInfo.DeregRequestHeight.Exists {
+		return fmt.Errorf("signing operator already requested deregistration")
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package registration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	avsdir "github.com/Layr-Labs/eigensdk-go/contracts/bindings/AVSDirectory"
+	dm "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+)
+
+var (
+	errorSelector = []byte{0x08, 0xc3, 0x79, 0xa0} // Error(string)
+	panicSelector = []byte{0x4e, 0x48, 0x7b, 0x71} // Panic(uint256)
+)
+
+// revertABIs are the contracts whose custom errors a registration tx can revert with.
+var revertABIs = []*bind.MetaData{
+	avs.MevcommitavsMetaData,
+	avsdir.ContractAVSDirectoryMetaData,
+	dm.ContractDelegationManagerMetaData,
+}
+
+// revertGuidance maps substrings of decoded revert reasons to what the operator
+// can do about them.
+var revertGuidance = []struct {
+	match    string
+	guidance string
+}{
+	{"EnforcedPause", "the AVS is paused, wait for it to be unpaused and retry"},
+	{"OwnableUnauthorizedAccount", "the method can only be called by the AVS owner"},
+	{"index is paused", "this EigenLayer function is paused, wait for it to be unpaused and retry"},
+	{"operator signature expired", "the registration signature expired before the tx was mined, " +
+		"rerun the command or increase --signature-expiry"},
+	{"operator already registered", "the operator is already registered with the AVS directory, check the status command"},
+	{"salt already spent", "the registration salt was already used, rerun the command to generate a new one"},
+	{"operator not registered to EigenLayer", "register the operator with EigenLayer first " +
+		"using `eigenlayer operator register`"},
+	{"signature not from signer", "the registration signature was not made by the operator, " +
+		"check the signer and operator address in the operator config"},
+}
+
+// RevertError is a tx revert decoded against the AVS and EigenLayer ABIs.
+type RevertError struct {
+	// Reason is the Error(string) message, the Panic(uint256) reason, or the
+	// custom error rendered as Name(args).
+	Reason string
+	// Guidance suggests how to resolve the revert, if known.
+	Guidance string
+	// Data is the raw revert data.
+	Data []byte
+
+	err error
+}
+
+func (e *RevertError) Error() string {
+	msg := "execution reverted: " + e.Reason
+	if e.Guidance != "" {
+		msg += " (" + e.Guidance + ")"
+	}
+	return msg
+}
+
+func (e *RevertError) Unwrap() error { return e.err }
+
+// decodeRevert converts an error carrying revert data from a call or gas
+// estimate into a *RevertError. Other errors are returned unchanged.
+func decodeRevert(err error) error {
+	if err == nil {
+		return nil
+	}
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	encoded, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(encoded)
+	if decodeErr != nil || len(data) < 4 {
+		return err
+	}
+	reason, decodeErr := decodeRevertData(data)
+	if decodeErr != nil {
+		reason = fmt.Sprintf("unknown error %s", hexutil.Encode(data))
+	}
+	return &RevertError{
+		Reason:   reason,
+		Guidance: guidanceFor(reason),
+		Data:     data,
+		err:      err,
+	}
+}
+
+// decodeRevertData decodes revert data as Error(string), Panic(uint256) or a
+// custom error of one of revertABIs.
+func decodeRevertData(data []byte) (string, error) {
+	if bytes.Equal(data[:4], errorSelector) || bytes.Equal(data[:4], panicSelector) {
+		return abi.UnpackRevert(data)
+	}
+	for _, md := range revertABIs {
+		parsed, err := md.GetAbi()
+		if err != nil {
+			return "", fmt.Errorf("failed to get abi: %w", err)
+		}
+		for _, abiErr := range parsed.Errors {
+			if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+				continue
+			}
+			values, err := abiErr.Inputs.Unpack(data[4:])
+			if err != nil {
+				return "", fmt.Errorf("failed to unpack %s: %w", abiErr.Name, err)
+			}
+			args := make([]string, len(values))
+			for i, v := range values {
+				args[i] = fmt.Sprintf("%v", v)
+			}
+			return abiErr.Name + "(" + strings.Join(args, ", ") + ")", nil
+		}
+	}
+	return "", fmt.Errorf("unknown error selector %s", hexutil.Encode(data[:4]))
+}
+
+func guidanceFor(reason string) string {
+	for _, g := range revertGuidance {
+		if strings.Contains(reason, g.match) {
+			return g.guidance
+		}
+	}
+	return ""
+}
+
+// getRevertReason replays a failed tx against the state its block started from
+// and returns why it reverted.
+func (c *Command) getRevertReason(ctx context.Context, receipt *ethtypes.Receipt) error {
+	tx, _, err := c.ethClient.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction: %w", err)
+	}
+
+	from, err := c.ethClient.TransactionSender(ctx, tx, receipt.BlockHash, receipt.TransactionIndex)
+	if err != nil {
+		return fmt.Errorf("failed to get transaction sender: %w", err)
+	}
+
+	msg := ethereum.CallMsg{
+		From:     from,
+		To:       tx.To(),
+		Gas:      tx.Gas(),
+		GasPrice: tx.GasPrice(),
+		Value:    tx.Value(),
+		Data:     tx.Data(),
+	}
+
+	// The state after the tx's block may include later txs of the block that
+	// satisfied or invalidated it, so the tx is replayed on top of its parent.
+	replayBlock := receipt.BlockNumber
+	if replayBlock.Sign() > 0 {
+		replayBlock = new(big.Int).Sub(replayBlock, big.NewInt(1))
+	}
+	_, err = c.ethClient.CallContract(ctx, msg, replayBlock)
+	if err == nil {
+		if receipt.GasUsed == tx.Gas() {
+			return fmt.Errorf("tx ran out of gas, gas limit %d", tx.Gas())
+		}
+		return fmt.Errorf("no revert reason found")
+	}
+	return decodeRevert(err)
+}
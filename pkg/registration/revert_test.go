@@ -0,0 +1,176 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/multiclient"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+type dataError struct {
+	data interface{}
+}
+
+func (e dataError) Error() string          { return "execution reverted" }
+func (e dataError) ErrorData() interface{} { return e.data }
+
+func encodeRevert(t *testing.T, sig string, typ string, value interface{}) string {
+	t.Helper()
+	selector := crypto.Keccak256([]byte(sig))[:4]
+	if typ == "" {
+		return hexutil.Encode(selector)
+	}
+	abiType, err := abi.NewType(typ, "", nil)
+	assert.NilError(t, err)
+	args, err := abi.Arguments{{Type: abiType}}.Pack(value)
+	assert.NilError(t, err)
+	return hexutil.Encode(append(selector, args...))
+}
+
+func TestDecodeRevert(t *testing.T) {
+	tests := []struct {
+		name             string
+		err              error
+		expectedReason   string
+		expectedGuidance string
+		expectedError    string
+	}{
+		{
+			name: "error string with guidance",
+			err: dataError{encodeRevert(t, "Error(string)", "string",
+				"AVSDirectory.registerOperatorToAVS: operator signature expired")},
+			expectedReason: "AVSDirectory.registerOperatorToAVS: operator signature expired",
+			expectedGuidance: "the registration signature expired before the tx was mined, " +
+				"rerun the command or increase --signature-expiry",
+		},
+		{
+			name:           "panic",
+			err:            dataError{encodeRevert(t, "Panic(uint256)", "uint256", common.Big1)},
+			expectedReason: "assert(false)",
+		},
+		{
+			name:             "avs custom error without args",
+			err:              dataError{encodeRevert(t, "EnforcedPause()", "", nil)},
+			expectedReason:   "EnforcedPause()",
+			expectedGuidance: "the AVS is paused, wait for it to be unpaused and retry",
+		},
+		{
+			name: "avs custom error with args",
+			err: dataError{encodeRevert(t, "OwnableUnauthorizedAccount(address)", "address",
+				common.HexToAddress("0x01"))},
+			expectedReason:   "OwnableUnauthorizedAccount(0x0000000000000000000000000000000000000001)",
+			expectedGuidance: "the method can only be called by the AVS owner",
+		},
+		{
+			name:           "unknown selector",
+			err:            dataError{"0xdeadbeef"},
+			expectedReason: "unknown error 0xdeadbeef",
+		},
+		{
+			name:          "no revert data",
+			err:           errors.New("connection refused"),
+			expectedError: "connection refused",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := decodeRevert(fmt.Errorf("failed to estimate gas: %w", tt.err))
+			var revertErr *RevertError
+			if tt.expectedError != "" {
+				assert.Assert(t, !errors.As(err, &revertErr))
+				assert.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			assert.Assert(t, errors.As(err, &revertErr))
+			assert.Equal(t, revertErr.Reason, tt.expectedReason)
+			assert.Equal(t, revertErr.Guidance, tt.expectedGuidance)
+			assert.Assert(t, errors.Is(err, tt.err))
+		})
+	}
+}
+
+// replayEthStandIn serves a failed tx over JSON-RPC and records the block it is
+// replayed at.
+type replayEthStandIn struct {
+	tx          *ethtypes.Transaction
+	from        common.Address
+	blockHash   common.Hash
+	revertData  string
+	replayBlock string
+}
+
+func (e *replayEthStandIn) GetTransactionByHash(hash common.Hash) (map[string]any, error) {
+	bz, err := e.tx.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]any
+	if err := json.Unmarshal(bz, &fields); err != nil {
+		return nil, err
+	}
+	fields["from"] = e.from
+	fields["blockHash"] = e.blockHash
+	fields["blockNumber"] = "0x64"
+	fields["transactionIndex"] = "0x0"
+	return fields, nil
+}
+
+func (e *replayEthStandIn) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	e.replayBlock = block
+	return nil, dataError{e.revertData}
+}
+
+func TestGetRevertReasonReplaysParentState(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	to := common.HexToAddress("0x01")
+	failedTx, err := ethtypes.SignNewTx(key, ethtypes.LatestSignerForChainID(big.NewInt(1)), &ethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       100000,
+		To:        &to,
+	})
+	assert.NilError(t, err)
+
+	standIn := &replayEthStandIn{
+		tx:         failedTx,
+		from:       crypto.PubkeyToAddress(key.PublicKey),
+		blockHash:  common.HexToHash("0x64"),
+		revertData: encodeRevert(t, "EnforcedPause()", "", nil),
+	}
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("eth", standIn))
+	t.Cleanup(server.Stop)
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	client, err := multiclient.Dial(context.Background(), []string{httpServer.URL}, time.Second, slog.Default())
+	assert.NilError(t, err)
+	c := &Command{ethClient: client, Logger: slog.Default()}
+
+	err = c.getRevertReason(context.Background(), &ethtypes.Receipt{
+		TxHash:      failedTx.Hash(),
+		BlockHash:   standIn.blockHash,
+		BlockNumber: big.NewInt(100),
+		GasUsed:     50000,
+	})
+	var revertErr *RevertError
+	assert.Assert(t, errors.As(err, &revertErr))
+	assert.Equal(t, "EnforcedPause()", revertErr.Reason)
+	assert.Equal(t, "0x63", standIn.replayBlock)
+}
@@ -0,0 +1,98 @@
+package registration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/urfave/cli/v2"
+)
+
+// OperatorStatus is the registration state of an operator as reported by the status command.
+type OperatorStatus struct {
+	Operator             string `json:"operator"`
+	AVSAddress           string `json:"avsAddress"`
+	IsEigenLayerOperator bool   `json:"isEigenLayerOperator"`
+	Registered           bool   `json:"registered"`
+	DeregRequested       bool   `json:"deregRequested"`
+	DeregRequestHeight   uint64 `json:"deregRequestHeight,omitempty"`
+	DeregPeriodBlocks    uint64 `json:"deregPeriodBlocks"`
+	CurrentBlock         uint64 `json:"currentBlock"`
+	BlocksRemaining      uint64 `json:"blocksRemaining"`
+	CanDeregister        bool   `json:"canDeregister"`
+}
+
+// Status reports the operator's registration state without unlocking the keystore
+// or sending any transaction.
+func (c *Command) Status(ctx *cli.Context) error {
+	if err := c.initializeClient(ctx); err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	operatorAddr := common.HexToAddress(c.OperatorConfig.Operator.Address)
+	opts := &bind.CallOpts{Context: ctx.Context}
+
+	blockNum, err := c.quorumClient.BlockNumber(ctx.Context)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	// Pin all reads to the same block so the report is consistent.
+	opts.BlockNumber = new(big.Int).SetUint64(blockNum)
+
+	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(opts, operatorAddr)
+	if err != nil {
+		return fmt.Errorf("failed to get operator reg info: %w", err)
+	}
+	operatorDeregPeriod, err := c.avsC.OperatorDeregPeriodBlocks(opts)
+	if err != nil {
+		return fmt.Errorf("failed to get operator deregistration period: %w", err)
+	}
+	isEigenOperator, err := c.dmC.IsOperator(opts, operatorAddr)
+	if err != nil {
+		return fmt.Errorf("failed to check if operator is registered with eigen core: %w", err)
+	}
+
+	status := OperatorStatus{
+		Operator:             operatorAddr.Hex(),
+		AVSAddress:           common.HexToAddress(c.MevCommitAVSAddress).Hex(),
+		IsEigenLayerOperator: isEigenOperator,
+		Registered:           operatorRegInfo.Exists,
+		DeregRequested:       operatorRegInfo.DeregRequestHeight.Exists,
+		DeregPeriodBlocks:    operatorDeregPeriod.Uint64(),
+		CurrentBlock:         blockNum,
+	}
+	if status.Registered && status.DeregRequested {
+		status.DeregRequestHeight = operatorRegInfo.DeregRequestHeight.BlockHeight.Uint64()
+		status.BlocksRemaining, status.CanDeregister = deregBlocksRemaining(
+			blockNum, status.DeregRequestHeight, status.DeregPeriodBlocks)
+	}
+
+	return writeStatus(ctx, c.OutputFormat, status)
+}
+
+func writeStatus(ctx *cli.Context, format string, status OperatorStatus) error {
+	w := ctx.App.Writer
+	if format == "json" {
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(status)
+	}
+
+	fmt.Fprintf(w, "Operator:                  %s\n", status.Operator)
+	fmt.Fprintf(w, "AVS address:               %s\n", status.AVSAddress)
+	fmt.Fprintf(w, "EigenLayer operator:       %t\n", status.IsEigenLayerOperator)
+	fmt.Fprintf(w, "Registered with AVS:       %t\n", status.Registered)
+	fmt.Fprintf(w, "Deregistration requested:  %t\n", status.DeregRequested)
+	if status.DeregRequested {
+		fmt.Fprintf(w, "Dereg request height:      %d\n", status.DeregRequestHeight)
+	}
+	fmt.Fprintf(w, "Dereg period (blocks):     %d\n", status.DeregPeriodBlocks)
+	fmt.Fprintf(w, "Current block:             %d\n", status.CurrentBlock)
+	if status.DeregRequested {
+		fmt.Fprintf(w, "Blocks remaining:          %d\n", status.BlocksRemaining)
+		fmt.Fprintf(w, "Can deregister:            %t\n", status.CanDeregister)
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package registration
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
+	dm "github.com/Layr-Labs/eigensdk-go/contracts/bindings/DelegationManager"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+	"gotest.tools/assert"
+)
+
+// statusEthStandIn serves the AVS and delegation manager reads of the status
+// command over JSON-RPC, recording the block every call is made at.
+type statusEthStandIn struct {
+	t           *testing.T
+	head        uint64
+	regInfo     avs.IMevCommitAVSOperatorRegistrationInfo
+	deregPeriod int64
+	isOperator  bool
+
+	mu         sync.Mutex
+	callBlocks []string
+}
+
+func (e *statusEthStandIn) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(offlineChainID)
+}
+
+func (e *statusEthStandIn) BlockNumber() hexutil.Uint64 {
+	return hexutil.Uint64(e.head)
+}
+
+func (e *statusEthStandIn) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	e.mu.Lock()
+	e.callBlocks = append(e.callBlocks, block)
+	e.mu.Unlock()
+
+	input, ok := args["input"].(string)
+	if !ok {
+		input, _ = args["data"].(string)
+	}
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+	assert.NilError(e.t, err)
+	dmABI, err := dm.ContractDelegationManagerMetaData.GetAbi()
+	assert.NilError(e.t, err)
+
+	pack := func(contractABI *abi.ABI, name string, values ...any) (hexutil.Bytes, error) {
+		return contractABI.Methods[name].Outputs.Pack(values...)
+	}
+	switch selector := string(data[:4]); selector {
+	case string(avsABI.Methods["getOperatorRegInfo"].ID):
+		return pack(avsABI, "getOperatorRegInfo", e.regInfo)
+	case string(avsABI.Methods["operatorDeregPeriodBlocks"].ID):
+		return pack(avsABI, "operatorDeregPeriodBlocks", big.NewInt(e.deregPeriod))
+	case string(dmABI.Methods["isOperator"].ID):
+		return pack(dmABI, "isOperator", e.isOperator)
+	default:
+		return nil, fmt.Errorf("unexpected call %x", data[:4])
+	}
+}
+
+func TestStatus(t *testing.T) {
+	operator := common.HexToAddress("0x0000000000000000000000000000000000000abc")
+	deregRequestedAt := func(height int64) avs.IMevCommitAVSOperatorRegistrationInfo {
+		return avs.IMevCommitAVSOperatorRegistrationInfo{
+			Exists: true,
+			DeregRequestHeight: avs.EventHeightLibEventHeight{
+				Exists:      true,
+				BlockHeight: big.NewInt(height),
+			},
+		}
+	}
+	notRegistered := avs.IMevCommitAVSOperatorRegistrationInfo{
+		DeregRequestHeight: avs.EventHeightLibEventHeight{BlockHeight: big.NewInt(0)},
+	}
+
+	tests := []struct {
+		name           string
+		format         string
+		regInfo        avs.IMevCommitAVSOperatorRegistrationInfo
+		isOperator     bool
+		expectedOutput string
+	}{
+		{
+			name:    "not registered",
+			format:  "text",
+			regInfo: notRegistered,
+			expectedOutput: "Operator:                  " + operator.Hex() + "\n" +
+				"AVS address:               " + offlineAVS.Hex() + "\n" +
+				"EigenLayer operator:       false\n" +
+				"Registered with AVS:       false\n" +
+				"Deregistration requested:  false\n" +
+				"Dereg period (blocks):     50\n" +
+				"Current block:             1000\n",
+		},
+		{
+			name:       "deregistration pending",
+			format:     "text",
+			regInfo:    deregRequestedAt(980),
+			isOperator: true,
+			expectedOutput: "Operator:                  " + operator.Hex() + "\n" +
+				"AVS address:               " + offlineAVS.Hex() + "\n" +
+				"EigenLayer operator:       true\n" +
+				"Registered with AVS:       true\n" +
+				"Deregistration requested:  true\n" +
+				"Dereg request height:      980\n" +
+				"Dereg period (blocks):     50\n" +
+				"Current block:             1000\n" +
+				"Blocks remaining:          30\n" +
+				"Can deregister:            false\n",
+		},
+		{
+			name:       "deregistration pending, json",
+			format:     "json",
+			regInfo:    deregRequestedAt(980),
+			isOperator: true,
+			expectedOutput: `{
+  "operator": "` + operator.Hex() + `",
+  "avsAddress": "` + offlineAVS.Hex() + `",
+  "isEigenLayerOperator": true,
+  "registered": true,
+  "deregRequested": true,
+  "deregRequestHeight": 980,
+  "deregPeriodBlocks": 50,
+  "currentBlock": 1000,
+  "blocksRemaining": 30,
+  "canDeregister": false
+}
+`,
+		},
+		{
+			name:       "deregistration period passed, json",
+			format:     "json",
+			regInfo:    deregRequestedAt(900),
+			isOperator: true,
+			expectedOutput: `{
+  "operator": "` + operator.Hex() + `",
+  "avsAddress": "` + offlineAVS.Hex() + `",
+  "isEigenLayerOperator": true,
+  "registered": true,
+  "deregRequested": true,
+  "deregRequestHeight": 900,
+  "deregPeriodBlocks": 50,
+  "currentBlock": 1000,
+  "blocksRemaining": 0,
+  "canDeregister": true
+}
+`,
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			standIn := &statusEthStandIn{
+				t:           t,
+				head:        1000,
+				regInfo:     tc.regInfo,
+				deregPeriod: 50,
+				isOperator:  tc.isOperator,
+			}
+			server := rpc.NewServer()
+			assert.NilError(t, server.RegisterName("eth", standIn))
+			t.Cleanup(server.Stop)
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+
+			operConfig := &eigenclitypes.OperatorConfig{ELDelegationManagerAddress: offlineAVSDir.Hex()}
+			operConfig.Operator.Address = operator.Hex()
+			operConfig.ChainId = *offlineChainID
+			c := &Command{
+				OperatorConfig:      operConfig,
+				MevCommitAVSAddress: offlineAVS.Hex(),
+				RPCURLs:             []string{httpServer.URL},
+				OutputFormat:        tc.format,
+				Logger:              slog.Default(),
+			}
+			ctx := cliContext()
+			var out bytes.Buffer
+			ctx.App.Writer = &out
+
+			assert.NilError(t, c.Status(ctx))
+			assert.Equal(t, tc.expectedOutput, out.String())
+			// Every read is pinned to the block the report is for.
+			assert.DeepEqual(t, []string{"0x3e8", "0x3e8", "0x3e8"}, standIn.callBlocks)
+		})
+	}
+}
@@ -0,0 +1,458 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/params"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+)
+
+// sendTx submits the transaction built by buildTx according to the submission
+// mode. The returned receipt is nil in fire-and-forget mode.
+func (c *Command) sendTx(ctx context.Context, name string, buildTx tx.TxSubmitFunc) (*ethtypes.Receipt, error) {
+	if err := c.setGasLimit(ctx, name, buildTx); err != nil {
+		return nil, err
+	}
+	return c.sendWithGasLimit(ctx, name, buildTx)
+}
+
+// sendWithGasLimit is sendTx for a transaction whose gas limit is already set
+// in the transact opts.
+func (c *Command) sendWithGasLimit(ctx context.Context, name string, buildTx tx.TxSubmitFunc) (*ethtypes.Receipt, error) {
+	if err := c.checkBalance(ctx, name); err != nil {
+		return nil, err
+	}
+	signTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		unsent := *opts
+		unsent.NoSend = true
+		return buildTx(ctx, &unsent)
+	}
+	return c.submit(ctx, name, c.tOpts, signTx)
+}
+
+// submit sends the tx signed by signTx with opts and waits for it as the
+// submission mode decides: once, or resending it with boosted fees until it is
+// mined, publicly or through the private relay, or without waiting at all.
+// signTx must not send the tx itself. The returned receipt is nil in
+// fire-and-forget mode, and an error if the tx reverted.
+func (c *Command) submit(
+	ctx context.Context,
+	name string,
+	opts *bind.TransactOpts,
+	signTx tx.TxSubmitFunc,
+) (*ethtypes.Receipt, error) {
+	return c.submitWithMode(ctx, name, c.submissionMode(), opts, signTx)
+}
+
+// submitWithMode is submit with the given submission mode instead of the
+// configured one.
+func (c *Command) submitWithMode(
+	ctx context.Context,
+	name string,
+	mode SubmissionMode,
+	opts *bind.TransactOpts,
+	signTx tx.TxSubmitFunc,
+) (*ethtypes.Receipt, error) {
+	submitTx := func(
+		ctx context.Context,
+		opts *bind.TransactOpts,
+	) (*ethtypes.Transaction, error) {
+		signedTx, err := signTx(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if err := c.broadcastTx(ctx, signedTx); err != nil {
+			return signedTx, err
+		}
+		c.Logger.Info(name+" tx sent",
+			"txHash", signedTx.Hash().Hex(),
+			"nonce", signedTx.Nonce(),
+			"mode", mode,
+		)
+		return signedTx, nil
+	}
+
+	switch mode {
+	case SubmissionModeBoost, SubmissionModePrivate:
+		receipt, err := tx.WaitMinedWithRetry(ctx, opts, submitTx, c.ethClient, c.retryPolicy(), c.Logger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait for tx to be mined: %w", err)
+		}
+		if err := c.checkReceipt(ctx, receipt); err != nil {
+			return nil, err
+		}
+		return receipt, nil
+	}
+
+	gasTip, gasFeeCap := tx.FeeParams(opts)
+	if err := c.FeeCeiling.Check(gasTip, gasFeeCap, opts.GasLimit); err != nil {
+		return nil, err
+	}
+	sentTx, err := submitTx(ctx, opts)
+	switch err = tx.ClassifyError(err); {
+	case errors.Is(err, tx.ErrAlreadyKnown) && sentTx != nil:
+		c.Logger.Info(name+" tx already known", "txHash", sentTx.Hash().Hex(), "nonce", sentTx.Nonce())
+	case err != nil:
+		return nil, fmt.Errorf("failed to submit tx: %w", err)
+	}
+	return c.awaitReceipt(ctx, sentTx)
+}
+
+// broadcastTx sends a signed tx to the RPC endpoint, or in private mode to the
+// private relay, targeting the next blocks. Errors are classified with
+// tx.ClassifyError.
+func (c *Command) broadcastTx(ctx context.Context, signedTx *ethtypes.Transaction) error {
+	if c.submissionMode() == SubmissionModePrivate {
+		head, err := c.ethClient.BlockNumber(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to get block number: %w", err)
+		}
+		return c.relay.Send(ctx, signedTx, head+1, head+c.privateTargetBlocks())
+	}
+	return tx.ClassifyError(c.ethClient.SendTransaction(ctx, signedTx))
+}
+
+// awaitReceipt waits for a sent tx to be mined and confirmed, and checks it
+// succeeded. In fire-and-forget mode it returns a nil receipt right away.
+func (c *Command) awaitReceipt(ctx context.Context, sentTx *ethtypes.Transaction) (*ethtypes.Receipt, error) {
+	if c.submissionMode() == SubmissionModeFireAndForget {
+		c.Logger.Info("not waiting for tx to be mined", "txHash", sentTx.Hash().Hex(), "nonce", sentTx.Nonce())
+		return nil, nil
+	}
+	c.Logger.Info("waiting for tx to be mined", "txHash", sentTx.Hash().Hex(), "nonce", sentTx.Nonce())
+	receipt, err := c.waitConfirmed(ctx, sentTx)
+	if err != nil {
+		return nil, err
+	}
+	c.FeeCeiling.RecordReceipt(receipt)
+	if err := c.checkReceipt(ctx, receipt); err != nil {
+		return nil, err
+	}
+	return receipt, nil
+}
+
+// checkReceipt returns why a mined tx reverted, or nil if it succeeded.
+func (c *Command) checkReceipt(ctx context.Context, receipt *ethtypes.Receipt) error {
+	if receipt.Status != ethtypes.ReceiptStatusSuccessful {
+		errRevertReason := c.getRevertReason(ctx, receipt)
+		return fmt.Errorf("receipt status unsuccessful: %d, %w", receipt.Status, errRevertReason)
+	}
+	return nil
+}
+
+// waitConfirmed waits for a tx sent without boosting to be mined and reach the
+// configured confirmations. A tx reorged out before then is sent again.
+func (c *Command) waitConfirmed(ctx context.Context, signedTx *ethtypes.Transaction) (*ethtypes.Receipt, error) {
+	for {
+		receipt, err := c.waitMined(ctx, signedTx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to wait for tx to be mined: %w", err)
+		}
+		receipt, err = tx.WaitConfirmed(ctx, c.ethClient, receipt, c.Confirmations, c.Logger)
+		if !errors.Is(err, tx.ErrReorged) {
+			return receipt, err
+		}
+		c.Logger.Warn("tx reorged out before enough confirmations, sending it again", "error", err)
+		err = c.broadcastTx(ctx, signedTx)
+		if err != nil && !errors.Is(err, tx.ErrAlreadyKnown) && !errors.Is(err, tx.ErrNonceTooLow) {
+			return nil, fmt.Errorf("failed to resend tx: %w", err)
+		}
+	}
+}
+
+// waitMined waits for a sent tx to be mined. In private mode, the tx is sent to
+// the relay again each time the blocks it targets pass without it.
+func (c *Command) waitMined(ctx context.Context, signedTx *ethtypes.Transaction) (*ethtypes.Receipt, error) {
+	txs := []*ethtypes.Transaction{signedTx}
+	if c.submissionMode() != SubmissionModePrivate {
+		return tx.WaitAnyMined(ctx, c.ethClient, txs, c.Logger)
+	}
+	for {
+		head, err := c.ethClient.BlockNumber(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get block number: %w", err)
+		}
+		targetCtx, cancel := context.WithCancel(ctx)
+		go func() {
+			lastBlock := head + c.privateTargetBlocks()
+			if tx.WaitForBlock(targetCtx, c.ethClient, lastBlock, c.retryPolicy().SubscribeHeads, c.Logger) == nil {
+				cancel()
+			}
+		}()
+		receipt, err := tx.WaitAnyMined(targetCtx, c.ethClient, txs, c.Logger)
+		cancel()
+		if !errors.Is(err, context.Canceled) || ctx.Err() != nil {
+			return receipt, err
+		}
+		c.Logger.Info("tx not included in target blocks, sending it to the relay again",
+			"txHash", signedTx.Hash().Hex())
+		err = c.broadcastTx(ctx, signedTx)
+		if err != nil && !errors.Is(err, tx.ErrAlreadyKnown) {
+			return nil, fmt.Errorf("failed to resend tx: %w", err)
+		}
+	}
+}
+
+// setGasLimit estimates the gas needed by the transaction built by buildTx and
+// sets the transact opts gas limit to the estimate plus the configured margin.
+func (c *Command) setGasLimit(ctx context.Context, name string, buildTx tx.TxSubmitFunc) error {
+	estimate, err := c.estimateGas(ctx, name, buildTx)
+	if err != nil {
+		return err
+	}
+	return c.setGasLimitFromEstimate(name, estimate)
+}
+
+// setGasLimitFromEstimate sets the transact opts gas limit to a gas estimate
+// plus the configured margin.
+func (c *Command) setGasLimitFromEstimate(name string, estimate uint64) error {
+	gasLimit, err := c.gasLimitWithMargin(name, estimate)
+	if err != nil {
+		return err
+	}
+	c.tOpts.GasLimit = gasLimit
+	return nil
+}
+
+// checkBalance fails with tx.ErrInsufficientFunds if the signing account cannot
+// pay for the worst case cost of the tx, including every boost when boosting.
+func (c *Command) checkBalance(ctx context.Context, name string) error {
+	gasTip, gasFeeCap := tx.FeeParams(c.tOpts)
+	cost := new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(c.tOpts.GasLimit))
+	if mode := c.submissionMode(); mode == SubmissionModeBoost || mode == SubmissionModePrivate {
+		cost = c.retryPolicy().WorstCaseCost(c.tOpts.GasLimit, gasTip, gasFeeCap)
+	}
+	if c.tOpts.Value != nil {
+		cost.Add(cost, c.tOpts.Value)
+	}
+	balance, err := c.ethClient.BalanceAt(ctx, c.tOpts.From, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get balance: %w", err)
+	}
+	if balance.Cmp(cost) < 0 {
+		return fmt.Errorf("%w: %s may cost up to %s ETH but %s has %s ETH, short by %s ETH",
+			tx.ErrInsufficientFunds, name, formatEther(cost), c.tOpts.From.Hex(),
+			formatEther(balance), formatEther(new(big.Int).Sub(cost, balance)))
+	}
+	c.Logger.Debug("balance covers worst case cost", "name", name, "cost", cost, "balance", balance)
+	return nil
+}
+
+// formatEther renders a wei amount in ETH without trailing zeros.
+func formatEther(wei *big.Int) string {
+	eth := new(big.Rat).SetFrac(wei, big.NewInt(params.Ether)).FloatString(18)
+	eth = strings.TrimRight(eth, "0")
+	return strings.TrimSuffix(eth, ".")
+}
+
+// estimateGas estimates the gas needed by the transaction built by buildTx.
+func (c *Command) estimateGas(ctx context.Context, name string, buildTx tx.TxSubmitFunc) (uint64, error) {
+	opts := *c.tOpts
+	opts.Context = ctx
+	opts.NoSend = true
+	// A zero gas limit makes bind estimate it. The draft is never broadcast,
+	// so it is left unsigned.
+	opts.GasLimit = 0
+	opts.Signer = func(_ common.Address, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+		return tx, nil
+	}
+
+	draft, err := buildTx(ctx, &opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to estimate gas for %s: %w", name, decodeRevert(err))
+	}
+	return draft.Gas(), nil
+}
+
+// gasLimitWithMargin applies the configured safety margin and cap to a gas estimate.
+func (c *Command) gasLimitWithMargin(name string, estimate uint64) (uint64, error) {
+	gasLimit, err := tx.GasLimitWithMargin(estimate, c.GasLimitMultiplier, c.MaxGasLimit)
+	if err != nil {
+		return 0, fmt.Errorf("failed to set gas limit for %s: %w", name, err)
+	}
+	c.Logger.Info("gas limit set from estimate",
+		"name", name,
+		"gasEstimate", estimate,
+		"multiplier", c.GasLimitMultiplier,
+		"maxGasLimit", c.MaxGasLimit,
+		"gasLimit", gasLimit,
+	)
+	return gasLimit, nil
+}
+
+// dryRun builds and signs the transaction produced by buildTx without broadcasting it,
+// then simulates it against the latest block and reports what would be sent.
+func (c *Command) dryRun(ctx context.Context, name string, buildTx tx.TxSubmitFunc) error {
+	if err := c.setGasLimit(ctx, name, buildTx); err != nil {
+		c.Logger.Warn("dry run: expected outcome", "outcome", "revert", "error", err)
+		return fmt.Errorf("dry run: %w", err)
+	}
+	return c.dryRunWithGasLimit(ctx, name, buildTx)
+}
+
+// dryRunWithGasLimit is dryRun for a transaction whose gas limit is already set
+// in the transact opts.
+func (c *Command) dryRunWithGasLimit(ctx context.Context, name string, buildTx tx.TxSubmitFunc) error {
+	if err := c.checkBalance(ctx, name); err != nil {
+		c.Logger.Warn("dry run: expected outcome", "outcome", "insufficient funds", "error", err)
+		return fmt.Errorf("dry run: %w", err)
+	}
+
+	opts := *c.tOpts
+	opts.Context = ctx
+	opts.NoSend = true
+
+	signedTx, err := buildTx(ctx, &opts)
+	if err != nil {
+		return fmt.Errorf("failed to build tx: %w", err)
+	}
+
+	blockNum, err := c.ethClient.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	method, args, err := decodeCalldata(signedTx.Data())
+	if err != nil {
+		return fmt.Errorf("failed to decode calldata: %w", err)
+	}
+	c.Logger.Info("dry run: transaction",
+		"name", name,
+		"from", opts.From.Hex(),
+		"to", signedTx.To().Hex(),
+		"nonce", signedTx.Nonce(),
+		"chainID", signedTx.ChainId(),
+		"value", signedTx.Value(),
+		"txHash", signedTx.Hash().Hex(),
+	)
+	c.Logger.Info("dry run: calldata", "method", method, "args", args, "data", hexutil.Encode(signedTx.Data()))
+	c.Logger.Info("dry run: gas params",
+		"txType", signedTx.Type(),
+		"gasLimit", signedTx.Gas(),
+		"gasTipCap", signedTx.GasTipCap(),
+		"gasFeeCap", signedTx.GasFeeCap(),
+	)
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode signed tx: %w", err)
+	}
+	c.Logger.Debug("dry run: signed tx", "rawTx", hexutil.Encode(rawTx))
+
+	msg := ethereum.CallMsg{
+		From:      opts.From,
+		To:        signedTx.To(),
+		GasFeeCap: signedTx.GasFeeCap(),
+		GasTipCap: signedTx.GasTipCap(),
+		Value:     signedTx.Value(),
+		Data:      signedTx.Data(),
+	}
+	msg.AccessList = signedTx.AccessList()
+	if signedTx.Type() != ethtypes.DynamicFeeTxType {
+		msg.GasFeeCap, msg.GasTipCap, msg.GasPrice = nil, nil, signedTx.GasPrice()
+	}
+	gasEstimate, err := c.ethClient.EstimateGas(ctx, msg)
+	if err != nil {
+		err = decodeRevert(err)
+		c.Logger.Warn("dry run: expected outcome", "block", blockNum, "outcome", "revert", "error", err)
+		return fmt.Errorf("dry run: %s would revert at block %d: %w", name, blockNum, err)
+	}
+
+	msg.Gas = signedTx.Gas()
+	if _, err := c.ethClient.CallContract(ctx, msg, new(big.Int).SetUint64(blockNum)); err != nil {
+		err = decodeRevert(err)
+		c.Logger.Warn("dry run: expected outcome", "block", blockNum, "outcome", "revert", "error", err)
+		return fmt.Errorf("dry run: %s would revert at block %d: %w", name, blockNum, err)
+	}
+	if gasEstimate > signedTx.Gas() {
+		c.Logger.Warn("dry run: gas limit is below estimate", "gasLimit", signedTx.Gas(), "gasEstimate", gasEstimate)
+	}
+
+	c.Logger.Info("dry run: expected outcome",
+		"block", blockNum,
+		"outcome", "success",
+		"gasEstimate", gasEstimate,
+	)
+	c.Logger.Info("dry run complete, transaction was not broadcast", "name", name)
+	return nil
+}
+
+// decodeCalldata decodes calldata for a mev-commit AVS method into its
+// signature and a human readable rendering of its arguments.
+func decodeCalldata(data []byte) (string, string, error) {
+	avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to get avs abi: %w", err)
+	}
+	if len(data) < 4 {
+		return "", "", fmt.Errorf("calldata too short: %d bytes", len(data))
+	}
+	method, err := avsABI.MethodById(data[:4])
+	if err != nil {
+		return "", "", err
+	}
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return "", "", fmt.Errorf("failed to unpack %s args: %w", method.Name, err)
+	}
+	args := make([]string, len(values))
+	for i, v := range values {
+		args[i] = argName(method.Inputs, i) + "=" + formatArg(reflect.ValueOf(v))
+	}
+	return method.Sig, strings.Join(args, " "), nil
+}
+
+func argName(args abi.Arguments, i int) string {
+	if args[i].Name != "" {
+		return args[i].Name
+	}
+	return fmt.Sprintf("arg%d", i)
+}
+
+// formatArg renders an unpacked ABI value with byte slices and arrays as hex.
+func formatArg(v reflect.Value) string {
+	switch val := v.Interface().(type) {
+	case common.Address:
+		return val.Hex()
+	case *big.Int:
+		return val.String()
+	case []byte:
+		return hexutil.Encode(val)
+	}
+	switch v.Kind() {
+	case reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			b := make([]byte, v.Len())
+			reflect.Copy(reflect.ValueOf(b), v)
+			return hexutil.Encode(b)
+		}
+		fallthrough
+	case reflect.Slice:
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = formatArg(v.Index(i))
+		}
+		return "[" + strings.Join(elems, ",") + "]"
+	case reflect.Struct:
+		fields := make([]string, v.NumField())
+		for i := range fields {
+			fields[i] = v.Type().Field(i).Name + ":" + formatArg(v.Field(i))
+		}
+		return "{" + strings.Join(fields, " ") + "}"
+	}
+	return fmt.Sprint(v.Interface())
+}
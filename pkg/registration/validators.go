@@ -0,0 +1,361 @@
+package registration
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"fmt"
+	"log/slog"
+	"math/big"
+
+	eigenpod "github.com/Layr-Labs/eigensdk-go/contracts/bindings/EigenPod"
+	eigenpodmanager "github.com/Layr-Labs/eigensdk-go/contracts/bindings/EigenPodManager"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/urfave/cli/v2"
+)
+
+// validatorStatusActive is the EigenPod status of a validator whose withdrawal
+// credentials have been verified and that has not exited.
+const validatorStatusActive = 1
+
+func (c *Command) RegisterValidators(ctx *cli.Context) error {
+	c.Logger.Info("Registering validators...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	pubkeys, err := readValidatorPubkeys(c.ValidatorPubkeys, c.ValidatorPubkeysFile, c.DepositDataFile)
+	if err != nil {
+		return err
+	}
+	podOwner := c.signer.Address()
+	if c.PodOwner != "" {
+		if !common.IsHexAddress(c.PodOwner) {
+			return fmt.Errorf("invalid pod owner address: %s", c.PodOwner)
+		}
+		podOwner = common.HexToAddress(c.PodOwner)
+	}
+	if err := c.checkValidatorRegPreconditions(ctx.Context, podOwner, pubkeys); err != nil {
+		return err
+	}
+
+	return c.sendValidatorBatches(ctx.Context, "RegisterValidatorsByPodOwners", pubkeys,
+		func(batch [][]byte) tx.TxSubmitFunc {
+			return func(ctx context.Context, opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+				tx, err := c.avsT.RegisterValidatorsByPodOwners(opts, [][][]byte{batch}, []common.Address{podOwner})
+				if err != nil {
+					return nil, fmt.Errorf("failed to register validators: %w", err)
+				}
+				return tx, nil
+			}
+		})
+}
+
+func (c *Command) RequestValidatorsDeregistration(ctx *cli.Context) error {
+	c.Logger.Info("Requesting validators deregistration...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	pubkeys, err := readValidatorPubkeys(c.ValidatorPubkeys, c.ValidatorPubkeysFile, c.DepositDataFile)
+	if err != nil {
+		return err
+	}
+	if err := c.checkValidatorRequestDeregPreconditions(ctx.Context, pubkeys); err != nil {
+		return err
+	}
+
+	return c.sendValidatorBatches(ctx.Context, "RequestValidatorsDeregistration", pubkeys,
+		func(batch [][]byte) tx.TxSubmitFunc {
+			return func(ctx context.Context, opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+				tx, err := c.avsT.RequestValidatorsDeregistration(opts, batch)
+				if err != nil {
+					return nil, fmt.Errorf("failed to request validators deregistration: %w", err)
+				}
+				return tx, nil
+			}
+		})
+}
+
+func (c *Command) DeregisterValidators(ctx *cli.Context) error {
+	c.Logger.Info("Deregistering validators...")
+	err := c.initialize(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to initialize: %w", err)
+	}
+
+	pubkeys, err := readValidatorPubkeys(c.ValidatorPubkeys, c.ValidatorPubkeysFile, c.DepositDataFile)
+	if err != nil {
+		return err
+	}
+	if err := c.checkValidatorDeregPreconditions(ctx.Context, pubkeys); err != nil {
+		return err
+	}
+
+	return c.sendValidatorBatches(ctx.Context, "DeregisterValidators", pubkeys,
+		func(batch [][]byte) tx.TxSubmitFunc {
+			return func(ctx context.Context, opts *bind.TransactOpts) (*ethtypes.Transaction, error) {
+				tx, err := c.avsT.DeregisterValidators(opts, batch)
+				if err != nil {
+					return nil, fmt.Errorf("failed to deregister validators: %w", err)
+				}
+				return tx, nil
+			}
+		})
+}
+
+// checkValidatorRegPreconditions verifies that the signer may register the given
+// validators of podOwner's EigenPod, and that each of them can be registered.
+func (c *Command) checkValidatorRegPreconditions(ctx context.Context, podOwner common.Address, pubkeys [][]byte) error {
+	opts := &bind.CallOpts{Context: ctx}
+
+	operator, err := c.checkPodOwnerOrOperator(ctx, podOwner)
+	if err != nil {
+		return err
+	}
+	operatorRegInfo, err := c.avsC.GetOperatorRegInfo(opts, operator)
+	if err != nil {
+		return fmt.Errorf("failed to get operator reg info: %w", err)
+	}
+	if !operatorRegInfo.Exists {
+		return fmt.Errorf("operator %s delegated to by pod owner is not registered with the avs", operator.Hex())
+	}
+	if operatorRegInfo.DeregRequestHeight.Exists {
+		return fmt.Errorf("operator %s delegated to by pod owner has requested deregistration", operator.Hex())
+	}
+
+	epmAddr, err := c.dmC.EigenPodManager(opts)
+	if err != nil {
+		return fmt.Errorf("failed to get eigen pod manager address: %w", err)
+	}
+	epmC, err := eigenpodmanager.NewContractEigenPodManagerCaller(epmAddr, c.ethClient)
+	if err != nil {
+		return fmt.Errorf("failed to create eigen pod manager: %w", err)
+	}
+	hasPod, err := epmC.HasPod(opts, podOwner)
+	if err != nil {
+		return fmt.Errorf("failed to check if pod owner has a pod: %w", err)
+	}
+	if !hasPod {
+		return fmt.Errorf("%s has no eigen pod", podOwner.Hex())
+	}
+	podAddr, err := epmC.GetPod(opts, podOwner)
+	if err != nil {
+		return fmt.Errorf("failed to get eigen pod address: %w", err)
+	}
+	c.Logger.Debug("eigen pod", "podOwner", podOwner.Hex(), "pod", podAddr.Hex(), "operator", operator.Hex())
+	podC, err := eigenpod.NewContractEigenPodCaller(podAddr, c.ethClient)
+	if err != nil {
+		return fmt.Errorf("failed to create eigen pod: %w", err)
+	}
+
+	for _, pubkey := range pubkeys {
+		status, err := podC.ValidatorStatus(opts, pubkey)
+		if err != nil {
+			return fmt.Errorf("failed to get status of validator %s: %w", hexutil.Encode(pubkey), err)
+		}
+		if status != validatorStatusActive {
+			return fmt.Errorf("validator %s is not active in eigen pod %s", hexutil.Encode(pubkey), podAddr.Hex())
+		}
+		regInfo, err := c.avsC.GetValidatorRegInfo(opts, pubkey)
+		if err != nil {
+			return fmt.Errorf("failed to get validator reg info: %w", err)
+		}
+		if regInfo.Exists {
+			return fmt.Errorf("validator %s already registered", hexutil.Encode(pubkey))
+		}
+	}
+	return nil
+}
+
+// checkValidatorRequestDeregPreconditions verifies that the signer may request
+// deregistration of each validator and that none has already requested it.
+func (c *Command) checkValidatorRequestDeregPreconditions(ctx context.Context, pubkeys [][]byte) error {
+	checkedPodOwners := make(map[common.Address]bool)
+	for _, pubkey := range pubkeys {
+		regInfo, err := c.avsC.GetValidatorRegInfo(&bind.CallOpts{Context: ctx}, pubkey)
+		if err != nil {
+			return fmt.Errorf("failed to get validator reg info: %w", err)
+		}
+		if !regInfo.Exists {
+			return fmt.Errorf("validator %s must be registered", hexutil.Encode(pubkey))
+		}
+		if regInfo.DeregRequestHeight.Exists {
+			return fmt.Errorf("validator %s already requested deregistration", hexutil.Encode(pubkey))
+		}
+		if !checkedPodOwners[regInfo.PodOwner] {
+			if _, err := c.checkPodOwnerOrOperator(ctx, regInfo.PodOwner); err != nil {
+				return err
+			}
+			checkedPodOwners[regInfo.PodOwner] = true
+		}
+	}
+	return nil
+}
+
+// checkValidatorDeregPreconditions verifies that the signer may deregister each
+// validator and that its deregistration period has passed.
+func (c *Command) checkValidatorDeregPreconditions(ctx context.Context, pubkeys [][]byte) error {
+	validatorDeregPeriod, err := c.avsC.ValidatorDeregPeriodBlocks(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return fmt.Errorf("failed to get validator deregistration period: %w", err)
+	}
+	blockNum, err := c.quorumClient.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+
+	checkedPodOwners := make(map[common.Address]bool)
+	for _, pubkey := range pubkeys {
+		regInfo, err := c.avsC.GetValidatorRegInfo(&bind.CallOpts{Context: ctx}, pubkey)
+		if err != nil {
+			return fmt.Errorf("failed to get validator reg info: %w", err)
+		}
+		if !regInfo.Exists {
+			return fmt.Errorf("validator %s must be registered", hexutil.Encode(pubkey))
+		}
+		if regInfo.FreezeHeight.Exists {
+			return fmt.Errorf("validator %s is frozen and cannot deregister", hexutil.Encode(pubkey))
+		}
+		if !regInfo.DeregRequestHeight.Exists {
+			return fmt.Errorf("validator %s must have requested deregistration", hexutil.Encode(pubkey))
+		}
+		blocksRemaining, ok := deregBlocksRemaining(blockNum,
+			regInfo.DeregRequestHeight.BlockHeight.Uint64(), validatorDeregPeriod.Uint64())
+		if !ok {
+			return fmt.Errorf("not enough blocks have passed since deregistration request of validator %s. "+
+				"Please wait %d more blocks", hexutil.Encode(pubkey), blocksRemaining)
+		}
+		if !checkedPodOwners[regInfo.PodOwner] {
+			if _, err := c.checkPodOwnerOrOperator(ctx, regInfo.PodOwner); err != nil {
+				return err
+			}
+			checkedPodOwners[regInfo.PodOwner] = true
+		}
+	}
+	return nil
+}
+
+// checkPodOwnerOrOperator verifies that the signer is either podOwner or the
+// operator podOwner is delegated to, and returns that operator.
+func (c *Command) checkPodOwnerOrOperator(ctx context.Context, podOwner common.Address) (common.Address, error) {
+	operator, err := c.dmC.DelegatedTo(&bind.CallOpts{Context: ctx}, podOwner)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to get operator delegated to by pod owner: %w", err)
+	}
+	if operator == (common.Address{}) {
+		return common.Address{}, fmt.Errorf("pod owner %s is not delegated to an operator", podOwner.Hex())
+	}
+	if signer := c.signer.Address(); signer != podOwner && signer != operator {
+		return common.Address{}, fmt.Errorf("signer %s is neither pod owner %s nor its delegated operator %s",
+			signer.Hex(), podOwner.Hex(), operator.Hex())
+	}
+	return operator, nil
+}
+
+// sendValidatorBatches splits pubkeys into batches of at most BatchSize validators
+// whose estimated gas limit fits within BatchGasBudget, and submits one
+// transaction per batch with nonces from the nonce manager. Each batch is priced
+// afresh, so fees boosted for one batch don't carry over to the next. A batch
+// estimated while fitting it to the budget isn't estimated again when sent.
+func (c *Command) sendValidatorBatches(
+	ctx context.Context,
+	name string,
+	pubkeys [][]byte,
+	build func(batch [][]byte) tx.TxSubmitFunc,
+) error {
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = len(pubkeys)
+	}
+	// fitBatch estimates the batch it settles on last.
+	var lastEstimate uint64
+	var lastEstimated int
+	gasLimit := func(batch [][]byte) (uint64, error) {
+		estimate, err := c.estimateGas(ctx, name, build(batch))
+		if err != nil {
+			return 0, err
+		}
+		lastEstimate, lastEstimated = estimate, len(batch)
+		return tx.GasLimitWithMargin(estimate, c.GasLimitMultiplier, 0)
+	}
+
+	for start, batchNum := 0, 1; start < len(pubkeys); batchNum++ {
+		if batchNum > 1 {
+			c.tOpts.Nonce = new(big.Int).SetUint64(c.nonceManager.Next())
+			if err := c.setFeeParams(ctx, c.tOpts); err != nil {
+				return fmt.Errorf("batch %d: %w", batchNum, err)
+			}
+		}
+
+		lastEstimated = 0
+		end, err := fitBatch(pubkeys, start, min(start+batchSize, len(pubkeys)), c.BatchGasBudget, gasLimit, c.Logger)
+		if err != nil {
+			return err
+		}
+		batch := pubkeys[start:end]
+		send, dryRun := c.sendTx, c.dryRun
+		if lastEstimated == len(batch) {
+			if err := c.setGasLimitFromEstimate(name, lastEstimate); err != nil {
+				return fmt.Errorf("batch %d: %w", batchNum, err)
+			}
+			send, dryRun = c.sendWithGasLimit, c.dryRunWithGasLimit
+		}
+		c.Logger.Info("submitting validator batch",
+			"name", name,
+			"batch", batchNum,
+			"validators", len(batch),
+			"remaining", len(pubkeys)-end,
+		)
+		if c.DryRun {
+			if err := dryRun(ctx, name, build(batch)); err != nil {
+				return err
+			}
+		} else {
+			receipt, err := send(ctx, name, build(batch))
+			if err != nil {
+				return fmt.Errorf("batch %d: %w", batchNum, err)
+			}
+			if receipt != nil {
+				c.Logger.Info(name+" batch complete", "batch", batchNum, "txHash", receipt.TxHash.Hex())
+			}
+		}
+		start = end
+	}
+
+	c.Logger.Info(name+" complete", "validators", len(pubkeys))
+	return nil
+}
+
+// fitBatch halves the batch pubkeys[start:end] until the gas limit returned by
+// gasLimit fits within budget, and returns the end of the batch. A zero budget
+// leaves the batch as is.
+func fitBatch(
+	pubkeys [][]byte,
+	start, end int,
+	budget uint64,
+	gasLimit func(batch [][]byte) (uint64, error),
+	logger *slog.Logger,
+) (int, error) {
+	for budget != 0 {
+		limit, err := gasLimit(pubkeys[start:end])
+		if err != nil {
+			return 0, err
+		}
+		if limit <= budget {
+			break
+		}
+		if end-start == 1 {
+			return 0, fmt.Errorf("gas limit %d for a single validator exceeds batch gas budget %d",
+				limit, budget)
+		}
+		logger.Debug("batch exceeds gas budget, halving",
+			"validators", end-start, "gasLimit", limit, "budget", budget)
+		end = start + (end-start)/2
+	}
+	return end, nil
+}
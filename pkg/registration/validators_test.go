@@ -0,0 +1,108 @@
+package registration
+
+import (
+	"errors"
+	"log/slog"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestFitBatch(t *testing.T) {
+	pubkeys := make([][]byte, 10)
+	// Each validator costs 50000 gas on top of a 30000 base.
+	perValidator := func(batch [][]byte) (uint64, error) {
+		return 30000 + 50000*uint64(len(batch)), nil
+	}
+
+	tests := []struct {
+		name          string
+		start, end    int
+		budget        uint64
+		gasLimit      func(batch [][]byte) (uint64, error)
+		expectedEnd   int
+		expectedCalls int
+		expectedError string
+	}{
+		{
+			name:        "no budget",
+			start:       0,
+			end:         10,
+			gasLimit:    perValidator,
+			expectedEnd: 10,
+		},
+		{
+			name:          "fits budget",
+			start:         0,
+			end:           10,
+			budget:        530000,
+			gasLimit:      perValidator,
+			expectedEnd:   10,
+			expectedCalls: 1,
+		},
+		{
+			name:          "halved once",
+			start:         0,
+			end:           10,
+			budget:        300000,
+			gasLimit:      perValidator,
+			expectedEnd:   5,
+			expectedCalls: 2,
+		},
+		{
+			name:          "halved until it fits",
+			start:         2,
+			end:           10,
+			budget:        130000,
+			gasLimit:      perValidator,
+			expectedEnd:   4,
+			expectedCalls: 3,
+		},
+		{
+			name:          "halved down to one validator",
+			start:         3,
+			end:           10,
+			budget:        80000,
+			gasLimit:      perValidator,
+			expectedEnd:   4,
+			expectedCalls: 3,
+		},
+		{
+			name:          "error, single validator exceeds budget",
+			start:         9,
+			end:           10,
+			budget:        79999,
+			gasLimit:      perValidator,
+			expectedCalls: 1,
+			expectedError: "gas limit 80000 for a single validator exceeds batch gas budget 79999",
+		},
+		{
+			name:   "error, estimate failed",
+			start:  0,
+			end:    10,
+			budget: 300000,
+			gasLimit: func([][]byte) (uint64, error) {
+				return 0, errors.New("execution reverted")
+			},
+			expectedCalls: 1,
+			expectedError: "execution reverted",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			calls := 0
+			gasLimit := func(batch [][]byte) (uint64, error) {
+				calls++
+				return tc.gasLimit(batch)
+			}
+			end, err := fitBatch(pubkeys, tc.start, tc.end, tc.budget, gasLimit, slog.Default())
+			assert.Equal(t, tc.expectedCalls, calls)
+			if tc.expectedError != "" {
+				assert.Error(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedEnd, end)
+		})
+	}
+}
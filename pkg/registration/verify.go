@@ -0,0 +1,102 @@
+package registration
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	avsdir "github.com/Layr-Labs/eigensdk-go/contracts/bindings/AVSDirectory"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// verifyContracts checks that the AVS, its AVS directory and the delegation
+// manager are deployed, that the AVS directory is the expected one and points to
+// the configured delegation manager, and that the AVS is not paused. Any problem
+// stops the command unless Force is set, so nothing is signed for the wrong
+// deployment.
+func (c *Command) verifyContracts(ctx context.Context) error {
+	opts := &bind.CallOpts{Context: ctx}
+	avsAddr := common.HexToAddress(c.MevCommitAVSAddress)
+	dmAddr := common.HexToAddress(c.OperatorConfig.ELDelegationManagerAddress)
+
+	var problems []string
+	hasCode := func(name string, addr common.Address) (bool, error) {
+		code, err := c.ethClient.CodeAt(ctx, addr, nil)
+		if err != nil {
+			return false, fmt.Errorf("failed to get code of %s: %w", name, err)
+		}
+		if len(code) == 0 {
+			problems = append(problems, fmt.Sprintf("no contract deployed at %s %s", name, addr.Hex()))
+			return false, nil
+		}
+		return true, nil
+	}
+
+	avsDeployed, err := hasCode("avs", avsAddr)
+	if err != nil {
+		return err
+	}
+	if _, err := hasCode("delegation manager", dmAddr); err != nil {
+		return err
+	}
+
+	if avsDeployed {
+		paused, err := c.avsC.Paused(opts)
+		if err != nil {
+			return fmt.Errorf("failed to check if avs is paused: %w", err)
+		}
+		if paused {
+			problems = append(problems, fmt.Sprintf("avs %s is paused", avsAddr.Hex()))
+		}
+
+		avsDirAddr, err := c.avsC.AvsDirectory(opts)
+		if err != nil {
+			return fmt.Errorf("failed to get avs dir address: %w", err)
+		}
+		expectedAVSDir := c.expectedAVSDirectoryAddress()
+		if expectedAVSDir != "" && avsDirAddr != common.HexToAddress(expectedAVSDir) {
+			problems = append(problems, fmt.Sprintf("avs uses avs directory %s, not the expected %s",
+				avsDirAddr.Hex(), expectedAVSDir))
+		}
+		avsDirDeployed, err := hasCode("avs directory", avsDirAddr)
+		if err != nil {
+			return err
+		}
+		if avsDirDeployed {
+			avsDir, err := avsdir.NewContractAVSDirectoryCaller(avsDirAddr, c.ethClient)
+			if err != nil {
+				return fmt.Errorf("failed to create avs dir: %w", err)
+			}
+			delegation, err := avsDir.Delegation(opts)
+			if err != nil {
+				return fmt.Errorf("failed to get delegation manager of avs dir: %w", err)
+			}
+			if delegation != dmAddr {
+				problems = append(problems, fmt.Sprintf("avs directory uses delegation manager %s, not the configured %s",
+					delegation.Hex(), dmAddr.Hex()))
+			}
+		}
+	}
+
+	if len(problems) == 0 {
+		c.Logger.Debug("contract wiring verified",
+			"avs", avsAddr.Hex(), "delegationManager", dmAddr.Hex())
+		return nil
+	}
+	if c.Force {
+		c.Logger.Warn("contract wiring checks failed, proceeding anyway", "problems", problems)
+		return nil
+	}
+	return fmt.Errorf("contract wiring checks failed, rerun with --force to proceed anyway: %s",
+		strings.Join(problems, "; "))
+}
+
+// expectedAVSDirectoryAddress returns the avs directory set by flag or network
+// preset, falling back to the one in the operator config.
+func (c *Command) expectedAVSDirectoryAddress() string {
+	if c.AVSDirectoryAddress != "" {
+		return c.AVSDirectoryAddress
+	}
+	return c.OperatorConfig.ELAVSDirectoryAddress
+}
@@ -0,0 +1,121 @@
+package registration
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http/httptest"
+	"testing"
+
+	eigenclitypes "github.com/Layr-Labs/eigenlayer-cli/pkg/types"
+	avsdir "github.com/Layr-Labs/eigensdk-go/contracts/bindings/AVSDirectory"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	avs "github.com/primev/mev-commit/contracts-abi/clients/MevCommitAVS"
+	"gotest.tools/assert"
+)
+
+var offlineDM = common.HexToAddress("0xA44151489861Fe9e3055d95adC98FbD462B948e7")
+
+// verifyEthStandIn serves a deployed AVS, AVS directory and delegation manager
+// over JSON-RPC, with the AVS pointing to offlineAVSDir.
+type verifyEthStandIn struct {
+	t *testing.T
+}
+
+func (e verifyEthStandIn) ChainId() *hexutil.Big {
+	return (*hexutil.Big)(offlineChainID)
+}
+
+func (e verifyEthStandIn) GetCode(addr common.Address, block string) hexutil.Bytes {
+	return hexutil.Bytes{0x60}
+}
+
+func (e verifyEthStandIn) Call(args map[string]any, block string) (hexutil.Bytes, error) {
+	input, ok := args["input"].(string)
+	if !ok {
+		input, _ = args["data"].(string)
+	}
+	data, err := hexutil.Decode(input)
+	if err != nil {
+		return nil, err
+	}
+	avsABI, err := avs.MevcommitavsMetaData.GetAbi()
+	assert.NilError(e.t, err)
+	avsDirABI, err := avsdir.ContractAVSDirectoryMetaData.GetAbi()
+	assert.NilError(e.t, err)
+
+	switch selector := string(data[:4]); selector {
+	case string(avsABI.Methods["paused"].ID):
+		return avsABI.Methods["paused"].Outputs.Pack(false)
+	case string(avsABI.Methods["avsDirectory"].ID):
+		return avsABI.Methods["avsDirectory"].Outputs.Pack(offlineAVSDir)
+	case string(avsDirABI.Methods["delegation"].ID):
+		return avsDirABI.Methods["delegation"].Outputs.Pack(offlineDM)
+	default:
+		return nil, fmt.Errorf("unexpected call %x", data[:4])
+	}
+}
+
+func TestVerifyContractsAVSDirectory(t *testing.T) {
+	otherAVSDir := common.HexToAddress("0x135DDa560e946695d6f155dACaFC6f1F25C1F5AF")
+	tests := []struct {
+		name          string
+		flagAVSDir    string
+		configAVSDir  string
+		expectedError string
+	}{
+		{
+			name: "no expected avs directory",
+		},
+		{
+			name:       "flag matches",
+			flagAVSDir: offlineAVSDir.Hex(),
+		},
+		{
+			name:         "operator config matches",
+			configAVSDir: offlineAVSDir.Hex(),
+		},
+		{
+			name:          "error, operator config differs",
+			configAVSDir:  otherAVSDir.Hex(),
+			expectedError: "avs uses avs directory " + offlineAVSDir.Hex() + ", not the expected " + otherAVSDir.Hex(),
+		},
+		{
+			name:         "flag overrides operator config",
+			flagAVSDir:   offlineAVSDir.Hex(),
+			configAVSDir: otherAVSDir.Hex(),
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := rpc.NewServer()
+			assert.NilError(t, server.RegisterName("eth", verifyEthStandIn{t: t}))
+			t.Cleanup(server.Stop)
+			httpServer := httptest.NewServer(server)
+			t.Cleanup(httpServer.Close)
+
+			operConfig := &eigenclitypes.OperatorConfig{
+				ELDelegationManagerAddress: offlineDM.Hex(),
+				ELAVSDirectoryAddress:      tc.configAVSDir,
+			}
+			operConfig.ChainId = *offlineChainID
+			c := &Command{
+				OperatorConfig:      operConfig,
+				MevCommitAVSAddress: offlineAVS.Hex(),
+				AVSDirectoryAddress: tc.flagAVSDir,
+				RPCURLs:             []string{httpServer.URL},
+				Logger:              slog.Default(),
+			}
+			assert.NilError(t, c.initializeClient(cliContext()))
+
+			err := c.verifyContracts(context.Background())
+			if tc.expectedError != "" {
+				assert.ErrorContains(t, err, tc.expectedError)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
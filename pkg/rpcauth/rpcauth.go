@@ -0,0 +1,197 @@
+package rpcauth
+
+import (
+	"bufio"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/gorilla/websocket"
+)
+
+// Environment variables holding credentials, read when the matching file is not
+// set. They keep secrets off the command line and out of config files.
+const (
+	EnvHeaders   = "RPC_HEADERS"
+	EnvJWTSecret = "RPC_JWT_SECRET"
+	EnvBasicAuth = "RPC_BASIC_AUTH"
+)
+
+var errMultipleAuthorization = errors.New(
+	"only one of headers, basic auth and jwt secret may set the Authorization header")
+
+// jwtSecretLength is the length in bytes of a JWT secret, as used by the engine API.
+const jwtSecretLength = 32
+
+// Config locates the credentials used to authenticate to RPC endpoints. Secrets
+// are read from files, or from the environment variables above if no file is set.
+type Config struct {
+	// HeadersFile holds HTTP headers sent with every request, one 'Name: value'
+	// per line. Blank lines and lines starting with '#' are skipped.
+	HeadersFile string
+	// JWTSecretFile holds a hex encoded 32 byte secret. Every request carries a
+	// bearer token signed with it using HS256, with the current time as its iat claim.
+	JWTSecretFile string
+	// BasicAuthFile holds 'user:password' for HTTP basic authentication.
+	BasicAuthFile string
+	// TLSCertFile and TLSKeyFile hold a PEM encoded client certificate and its key.
+	TLSCertFile string
+	TLSKeyFile  string
+	// TLSCAFile holds PEM encoded certificates trusted instead of the system ones.
+	TLSCAFile string
+}
+
+// ClientOptions returns the rpc client options applying the configured
+// credentials, over HTTP as well as WebSocket.
+func (c Config) ClientOptions() ([]rpc.ClientOption, error) {
+	var opts []rpc.ClientOption
+
+	headers, err := c.headers()
+	if err != nil {
+		return nil, err
+	}
+	authorization := headers.Get("Authorization") != ""
+
+	basicAuth, err := readSecret(c.BasicAuthFile, EnvBasicAuth)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read basic auth: %w", err)
+	}
+	if basicAuth != "" {
+		if !strings.Contains(basicAuth, ":") {
+			return nil, fmt.Errorf("invalid basic auth: must be 'user:password'")
+		}
+		if authorization {
+			return nil, errMultipleAuthorization
+		}
+		headers.Set("Authorization", "Basic "+base64.StdEncoding.EncodeToString([]byte(basicAuth)))
+		authorization = true
+	}
+	if len(headers) > 0 {
+		opts = append(opts, rpc.WithHeaders(headers))
+	}
+
+	jwtSecret, err := readSecret(c.JWTSecretFile, EnvJWTSecret)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read jwt secret: %w", err)
+	}
+	if jwtSecret != "" {
+		if authorization {
+			return nil, errMultipleAuthorization
+		}
+		secret, err := hexutil.Decode("0x" + strings.TrimPrefix(jwtSecret, "0x"))
+		if err != nil || len(secret) != jwtSecretLength {
+			return nil, fmt.Errorf("invalid jwt secret: must be %d hex encoded bytes", jwtSecretLength)
+		}
+		opts = append(opts, rpc.WithHTTPAuth(JWTAuth(secret)))
+	}
+
+	tlsConfig, err := c.tlsConfig()
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		opts = append(opts,
+			rpc.WithHTTPClient(&http.Client{Transport: transport}),
+			rpc.WithWebsocketDialer(websocket.Dialer{
+				Proxy:           http.ProxyFromEnvironment,
+				TLSClientConfig: tlsConfig,
+			}),
+		)
+	}
+	return opts, nil
+}
+
+// JWTAuth authenticates every request with a fresh HS256 token signed with
+// secret, whose iat claim is the time of the request.
+func JWTAuth(secret []byte) rpc.HTTPAuth {
+	return func(h http.Header) error {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+			"iat": jwt.NewNumericDate(time.Now()),
+		})
+		signed, err := token.SignedString(secret)
+		if err != nil {
+			return fmt.Errorf("failed to sign jwt: %w", err)
+		}
+		h.Set("Authorization", "Bearer "+signed)
+		return nil
+	}
+}
+
+// readSecret reads the secret in file, or in the environment variable env if no
+// file is set, with surrounding whitespace trimmed.
+func readSecret(file, env string) (string, error) {
+	if file == "" {
+		return strings.TrimSpace(os.Getenv(env)), nil
+	}
+	bz, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(bz)), nil
+}
+
+// headers parses the configured headers. Invalid lines are reported by number
+// only, as they may contain credentials.
+func (c Config) headers() (http.Header, error) {
+	raw, err := readSecret(c.HeadersFile, EnvHeaders)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read headers: %w", err)
+	}
+	headers := make(http.Header)
+	scanner := bufio.NewScanner(strings.NewReader(raw))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(text, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, fmt.Errorf("invalid header on line %d: must be 'Name: value'", line)
+		}
+		headers.Add(name, strings.TrimSpace(value))
+	}
+	return headers, nil
+}
+
+// tlsConfig loads the configured client certificate and trusted certificates,
+// returning nil if none are set.
+func (c Config) tlsConfig() (*tls.Config, error) {
+	if c.TLSCertFile == "" && c.TLSKeyFile == "" && c.TLSCAFile == "" {
+		return nil, nil
+	}
+	config := &tls.Config{MinVersion: tls.VersionTLS12}
+	if c.TLSCertFile != "" || c.TLSKeyFile != "" {
+		if c.TLSCertFile == "" || c.TLSKeyFile == "" {
+			return nil, fmt.Errorf("a tls client certificate requires both a cert and a key file")
+		}
+		cert, err := tls.LoadX509KeyPair(c.TLSCertFile, c.TLSKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load tls client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if c.TLSCAFile != "" {
+		bz, err := os.ReadFile(c.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tls ca file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(bz) {
+			return nil, fmt.Errorf("no certificates found in tls ca file %s", c.TLSCAFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
@@ -0,0 +1,239 @@
+package rpcauth_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"eigen-operator-cli/pkg/rpcauth"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/golang-jwt/jwt/v4"
+	"gotest.tools/assert"
+)
+
+const jwtSecret = "0x2a4c6e8f1b3d5a7c9e0f2b4d6a8c0e1f3b5d7a9c2e4f6a8c0b1d3e5f7a9c2b4d"
+
+type pingStandIn struct{}
+
+func (pingStandIn) Ping() string {
+	return "pong"
+}
+
+// recordingServer serves pingStandIn over JSON-RPC, recording the headers of the
+// last request and whether it came with a client certificate.
+type recordingServer struct {
+	*httptest.Server
+	mu         sync.Mutex
+	header     http.Header
+	clientCert bool
+}
+
+func newRecordingServer(t *testing.T, requireClientCert bool) *recordingServer {
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("test", pingStandIn{}))
+	t.Cleanup(server.Stop)
+
+	rs := &recordingServer{}
+	rs.Server = httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rs.mu.Lock()
+		rs.header = r.Header.Clone()
+		rs.clientCert = r.TLS != nil && len(r.TLS.PeerCertificates) > 0
+		rs.mu.Unlock()
+		server.ServeHTTP(w, r)
+	}))
+	if requireClientCert {
+		rs.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+		rs.StartTLS()
+	} else {
+		rs.Start()
+	}
+	t.Cleanup(rs.Close)
+	return rs
+}
+
+func writeFile(t *testing.T, name, content string) string {
+	path := filepath.Join(t.TempDir(), name)
+	assert.NilError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// writeClientCert writes a self-signed client certificate and its key, returning
+// their paths.
+func writeClientCert(t *testing.T) (string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NilError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "operator"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NilError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NilError(t, err)
+	certFile := writeFile(t, "cert.pem", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})))
+	keyFile := writeFile(t, "key.pem", string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})))
+	return certFile, keyFile
+}
+
+func TestClientOptions(t *testing.T) {
+	testCases := []struct {
+		name              string
+		config            func(t *testing.T, server *recordingServer) rpcauth.Config
+		env               map[string]string
+		requireClientCert bool
+		expectedHeaders   map[string]string
+		expectJWT         bool
+		expectClientCert  bool
+		errExpectedOutput string
+	}{
+		{
+			name: "headers from file",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{HeadersFile: writeFile(t, "headers",
+					"# provider credentials\nAuthorization: Bearer token\n\nx-api-key: key:with:colons\n")}
+			},
+			expectedHeaders: map[string]string{"Authorization": "Bearer token", "X-Api-Key": "key:with:colons"},
+		},
+		{
+			name:            "headers from env",
+			env:             map[string]string{rpcauth.EnvHeaders: "X-Api-Key: key"},
+			expectedHeaders: map[string]string{"X-Api-Key": "key"},
+		},
+		{
+			name: "basic auth from file",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{BasicAuthFile: writeFile(t, "basic", "operator:secret\n")}
+			},
+			expectedHeaders: map[string]string{
+				"Authorization": "Basic " + base64.StdEncoding.EncodeToString([]byte("operator:secret")),
+			},
+		},
+		{
+			name: "jwt secret from file",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{JWTSecretFile: writeFile(t, "jwt.hex", strings.TrimPrefix(jwtSecret, "0x")+"\n")}
+			},
+			expectJWT: true,
+		},
+		{
+			name:            "jwt secret from env with headers",
+			env:             map[string]string{rpcauth.EnvJWTSecret: jwtSecret, rpcauth.EnvHeaders: "X-Api-Key: key"},
+			expectedHeaders: map[string]string{"X-Api-Key": "key"},
+			expectJWT:       true,
+		},
+		{
+			name: "file takes precedence over env",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{HeadersFile: writeFile(t, "headers", "X-Api-Key: file")}
+			},
+			env:             map[string]string{rpcauth.EnvHeaders: "X-Api-Key: env"},
+			expectedHeaders: map[string]string{"X-Api-Key": "file"},
+		},
+		{
+			name: "tls client certificate",
+			config: func(t *testing.T, server *recordingServer) rpcauth.Config {
+				certFile, keyFile := writeClientCert(t)
+				ca := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+				return rpcauth.Config{TLSCertFile: certFile, TLSKeyFile: keyFile, TLSCAFile: writeFile(t, "ca.pem", string(ca))}
+			},
+			requireClientCert: true,
+			expectClientCert:  true,
+		},
+		{
+			name:              "error, invalid header",
+			env:               map[string]string{rpcauth.EnvHeaders: "X-Api-Key: key\nBearer token"},
+			errExpectedOutput: "invalid header on line 2: must be 'Name: value'",
+		},
+		{
+			name:              "error, invalid jwt secret",
+			env:               map[string]string{rpcauth.EnvJWTSecret: "0x1234"},
+			errExpectedOutput: "invalid jwt secret: must be 32 hex encoded bytes",
+		},
+		{
+			name:              "error, jwt secret and authorization header",
+			env:               map[string]string{rpcauth.EnvJWTSecret: jwtSecret, rpcauth.EnvHeaders: "Authorization: Bearer token"},
+			errExpectedOutput: "only one of headers, basic auth and jwt secret may set the Authorization header",
+		},
+		{
+			name:              "error, jwt secret and basic auth",
+			env:               map[string]string{rpcauth.EnvJWTSecret: jwtSecret, rpcauth.EnvBasicAuth: "operator:secret"},
+			errExpectedOutput: "only one of headers, basic auth and jwt secret may set the Authorization header",
+		},
+		{
+			name: "error, tls cert without key",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{TLSCertFile: "cert.pem"}
+			},
+			errExpectedOutput: "a tls client certificate requires both a cert and a key file",
+		},
+		{
+			name: "error, missing file",
+			config: func(t *testing.T, _ *recordingServer) rpcauth.Config {
+				return rpcauth.Config{JWTSecretFile: filepath.Join(t.TempDir(), "jwt.hex")}
+			},
+			errExpectedOutput: "failed to read jwt secret",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, env := range []string{rpcauth.EnvHeaders, rpcauth.EnvJWTSecret, rpcauth.EnvBasicAuth} {
+				t.Setenv(env, tc.env[env])
+			}
+			server := newRecordingServer(t, tc.requireClientCert)
+			var config rpcauth.Config
+			if tc.config != nil {
+				config = tc.config(t, server)
+			}
+
+			opts, err := config.ClientOptions()
+			if tc.errExpectedOutput != "" {
+				assert.ErrorContains(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+
+			client, err := rpc.DialOptions(context.Background(), server.URL, opts...)
+			assert.NilError(t, err)
+			defer client.Close()
+			var pong string
+			assert.NilError(t, client.CallContext(context.Background(), &pong, "test_ping"))
+			assert.Equal(t, "pong", pong)
+
+			server.mu.Lock()
+			defer server.mu.Unlock()
+			for name, value := range tc.expectedHeaders {
+				assert.Equal(t, value, server.header.Get(name))
+			}
+			assert.Equal(t, tc.expectClientCert, server.clientCert)
+			if tc.expectJWT {
+				bearer, ok := strings.CutPrefix(server.header.Get("Authorization"), "Bearer ")
+				assert.Assert(t, ok, "missing bearer token")
+				claims := jwt.MapClaims{}
+				_, err := jwt.ParseWithClaims(bearer, claims, func(token *jwt.Token) (any, error) {
+					return common.FromHex(jwtSecret), nil
+				}, jwt.WithValidMethods([]string{"HS256"}))
+				assert.NilError(t, err)
+				assert.Assert(t, claims.VerifyIssuedAt(time.Now().Unix(), true), "missing iat claim")
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Signer signs EIP-712 payloads and transactions on behalf of a single account.
+type Signer interface {
+	// Address returns the address of the signing account.
+	Address() common.Address
+	// SignTypedData signs an EIP-712 payload and returns a 65-byte [R || S || V]
+	// signature with V of 27 or 28.
+	SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error)
+	// SignTx signs tx for the given chain.
+	SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+}
+
+// HashSigner is implemented by signers that can sign an arbitrary 32-byte hash,
+// such as a digest computed on chain whose preimage isn't known.
+type HashSigner interface {
+	// SignHash signs hash and returns a 65-byte [R || S || V] signature with V
+	// of 27 or 28.
+	SignHash(ctx context.Context, hash common.Hash) ([]byte, error)
+}
+
+// NewTransactOpts returns transact opts for the signer's account whose
+// transactions are signed by s.
+func NewTransactOpts(ctx context.Context, s Signer, chainID *big.Int) *bind.TransactOpts {
+	return &bind.TransactOpts{
+		From: s.Address(),
+		Signer: func(address common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			if address != s.Address() {
+				return nil, bind.ErrNotAuthorized
+			}
+			return s.SignTx(ctx, tx, chainID)
+		},
+		Context: ctx,
+	}
+}
+
+// KeystoreSigner signs with an unlocked account of a local geth keystore.
+type KeystoreSigner struct {
+	keystore *keystore.KeyStore
+	account  accounts.Account
+}
+
+var (
+	_ Signer     = (*KeystoreSigner)(nil)
+	_ HashSigner = (*KeystoreSigner)(nil)
+)
+
+// NewKeystoreSigner returns a signer for account, which must already be unlocked in ks.
+func NewKeystoreSigner(ks *keystore.KeyStore, account accounts.Account) *KeystoreSigner {
+	return &KeystoreSigner{keystore: ks, account: account}
+}
+
+func (s *KeystoreSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *KeystoreSigner) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+	return s.SignHash(ctx, common.BytesToHash(hash))
+}
+
+func (s *KeystoreSigner) SignHash(_ context.Context, hash common.Hash) ([]byte, error) {
+	sig, err := s.keystore.SignHash(s.account, hash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign hash: %w", err)
+	}
+	// V is 0 or 1 from SignHash, but needs to be 27 or 28. See https://github.com/ethereum/go-ethereum/issues/19751
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+	return sig, nil
+}
+
+func (s *KeystoreSigner) SignTx(_ context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return s.keystore.SignTx(s.account, tx, chainID)
+}
@@ -0,0 +1,149 @@
+package signer
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Web3Signer signs through the eth1 JSON-RPC API of a Web3Signer compatible
+// remote signing service, so the key never leaves the service. Every signature
+// it returns is verified to come from the expected account.
+type Web3Signer struct {
+	client  *rpc.Client
+	address common.Address
+}
+
+var _ Signer = (*Web3Signer)(nil)
+
+// NewWeb3Signer connects to the signing service at url and checks that it holds
+// the key for address.
+func NewWeb3Signer(ctx context.Context, url string, address common.Address) (*Web3Signer, error) {
+	client, err := rpc.DialContext(ctx, url)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to web3signer: %w", err)
+	}
+	var available []common.Address
+	if err := client.CallContext(ctx, &available, "eth_accounts"); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("failed to list web3signer accounts: %w", err)
+	}
+	if !slices.Contains(available, address) {
+		client.Close()
+		return nil, fmt.Errorf("web3signer at %s has no key for %s", url, address.Hex())
+	}
+	return &Web3Signer{client: client, address: address}, nil
+}
+
+func (s *Web3Signer) Address() common.Address {
+	return s.address
+}
+
+func (s *Web3Signer) SignTypedData(ctx context.Context, typedData apitypes.TypedData) ([]byte, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to hash typed data: %w", err)
+	}
+
+	var sig hexutil.Bytes
+	if err := s.client.CallContext(ctx, &sig, "eth_signTypedData", s.address, typedData); err != nil {
+		return nil, fmt.Errorf("web3signer failed to sign typed data: %w", err)
+	}
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("web3signer returned signature of %d bytes", len(sig))
+	}
+	if sig[64] < 27 {
+		sig[64] += 27
+	}
+
+	recoverable := slices.Clone(sig)
+	recoverable[64] -= 27
+	pubKey, err := crypto.SigToPub(hash, recoverable)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover typed data signer: %w", err)
+	}
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != s.address {
+		return nil, fmt.Errorf("web3signer signed typed data with %s, expected %s", signer.Hex(), s.address.Hex())
+	}
+	return sig, nil
+}
+
+// web3SignerTxArgs are the eth_signTransaction params understood by Web3Signer.
+type web3SignerTxArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to,omitempty"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 hexutil.Bytes     `json:"data"`
+	ChainID              *hexutil.Big      `json:"chainId,omitempty"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+}
+
+func (s *Web3Signer) SignTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	args := web3SignerTxArgs{
+		From:  s.address,
+		To:    tx.To(),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: (*hexutil.Big)(tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  tx.Data(),
+	}
+	// Only the tx types the CLI sends are supported. An access list without
+	// EIP-1559 fee params requests an access-list tx.
+	switch tx.Type() {
+	case types.LegacyTxType:
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	case types.AccessListTxType:
+		// An empty access list must still be sent, rather than null.
+		accessList := append(types.AccessList{}, tx.AccessList()...)
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		args.ChainID = (*hexutil.Big)(chainID)
+		args.AccessList = &accessList
+	case types.DynamicFeeTxType:
+		args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+		args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+	default:
+		return nil, fmt.Errorf("unsupported tx type for web3signer: %d", tx.Type())
+	}
+
+	var raw hexutil.Bytes
+	if err := s.client.CallContext(ctx, &raw, "eth_signTransaction", args); err != nil {
+		return nil, fmt.Errorf("web3signer failed to sign tx: %w", err)
+	}
+	signedTx := new(types.Transaction)
+	if err := signedTx.UnmarshalBinary(raw); err != nil {
+		return nil, fmt.Errorf("failed to decode tx signed by web3signer: %w", err)
+	}
+
+	// The signing hash covers every field of the tx, so comparing it ensures the
+	// service signed exactly the tx that was requested, for the right chain.
+	txSigner := types.LatestSignerForChainID(chainID)
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		return nil, fmt.Errorf("web3signer signed a different tx than requested")
+	}
+	sender, err := types.Sender(txSigner, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover sender of tx signed by web3signer: %w", err)
+	}
+	if sender != s.address {
+		return nil, fmt.Errorf("web3signer signed tx with %s, expected %s", sender.Hex(), s.address.Hex())
+	}
+	return signedTx, nil
+}
+
+// Close closes the connection to the signing service.
+func (s *Web3Signer) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,269 @@
+package signer_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"eigen-operator-cli/pkg/signer"
+	"fmt"
+	"math/big"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"gotest.tools/assert"
+)
+
+// Default anvil/hardhat key-pair, also used by the test keystore.
+const testPrivateKey = "ac0974bec39a17e36ba4a6b4d238ff944bacb478cbed5efcae784d7bf4f2ff80"
+
+var testChainID = big.NewInt(31337)
+
+type txArgs struct {
+	From                 common.Address    `json:"from"`
+	To                   *common.Address   `json:"to"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 hexutil.Bytes     `json:"data"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+	AccessList           *types.AccessList `json:"accessList"`
+}
+
+// mockWeb3Signer is a local stand-in for the eth1 JSON-RPC API of Web3Signer.
+// tamperTx lets a test alter a tx before it is signed.
+type mockWeb3Signer struct {
+	key      *ecdsa.PrivateKey
+	tamperTx func(*types.DynamicFeeTx)
+}
+
+func (m *mockWeb3Signer) Accounts() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(m.key.PublicKey)}
+}
+
+func (m *mockWeb3Signer) SignTypedData(address common.Address, typedData apitypes.TypedData) (hexutil.Bytes, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := crypto.Sign(hash, m.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27
+	return sig, nil
+}
+
+// SignTransaction picks the tx type from the params as geth does: EIP-1559 fee
+// params make a dynamic fee tx, otherwise an access list makes an access-list tx.
+func (m *mockWeb3Signer) SignTransaction(args txArgs) (hexutil.Bytes, error) {
+	var inner types.TxData
+	switch {
+	case args.MaxFeePerGas != nil && args.MaxPriorityFeePerGas != nil:
+		dynamicFeeTx := &types.DynamicFeeTx{
+			ChainID:   testChainID,
+			Nonce:     uint64(args.Nonce),
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		}
+		if m.tamperTx != nil {
+			m.tamperTx(dynamicFeeTx)
+		}
+		inner = dynamicFeeTx
+	case args.GasPrice != nil && args.AccessList != nil:
+		if args.ChainID == nil || args.ChainID.ToInt().Cmp(testChainID) != 0 {
+			return nil, fmt.Errorf("chain id mismatch")
+		}
+		inner = &types.AccessListTx{
+			ChainID:    testChainID,
+			Nonce:      uint64(args.Nonce),
+			GasPrice:   args.GasPrice.ToInt(),
+			Gas:        uint64(args.Gas),
+			To:         args.To,
+			Value:      args.Value.ToInt(),
+			Data:       args.Data,
+			AccessList: *args.AccessList,
+		}
+	case args.GasPrice != nil:
+		inner = &types.LegacyTx{
+			Nonce:    uint64(args.Nonce),
+			GasPrice: args.GasPrice.ToInt(),
+			Gas:      uint64(args.Gas),
+			To:       args.To,
+			Value:    args.Value.ToInt(),
+			Data:     args.Data,
+		}
+	default:
+		return nil, fmt.Errorf("missing fee params")
+	}
+	signedTx, err := types.SignNewTx(m.key, types.LatestSignerForChainID(testChainID), inner)
+	if err != nil {
+		return nil, err
+	}
+	return signedTx.MarshalBinary()
+}
+
+func newMockWeb3Signer(t *testing.T, mock *mockWeb3Signer) string {
+	t.Helper()
+	server := rpc.NewServer()
+	assert.NilError(t, server.RegisterName("eth", mock))
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(func() {
+		httpServer.Close()
+		server.Stop()
+	})
+	return httpServer.URL
+}
+
+func testTypedData() apitypes.TypedData {
+	return apitypes.TypedData{
+		Types: apitypes.Types{
+			"EIP712Domain": {
+				{Name: "name", Type: "string"},
+				{Name: "chainId", Type: "uint256"},
+				{Name: "verifyingContract", Type: "address"},
+			},
+			"Mail": {
+				{Name: "to", Type: "address"},
+				{Name: "contents", Type: "string"},
+			},
+		},
+		PrimaryType: "Mail",
+		Domain: apitypes.TypedDataDomain{
+			Name:              "Test",
+			ChainId:           math.NewHexOrDecimal256(31337),
+			VerifyingContract: "0x5FC8d32690cc91D4c39d9d3abcBD16989F875707",
+		},
+		Message: apitypes.TypedDataMessage{
+			"to":       "0x0000000000000000000000000000000000000001",
+			"contents": "hello",
+		},
+	}
+}
+
+func testTx() *types.Transaction {
+	to := common.HexToAddress("0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   testChainID,
+		Nonce:     7,
+		GasTipCap: big.NewInt(1000),
+		GasFeeCap: big.NewInt(2000),
+		Gas:       100000,
+		To:        &to,
+		Data:      []byte{0xde, 0xad, 0xbe, 0xef},
+	})
+}
+
+func TestWeb3Signer(t *testing.T) {
+	key, err := crypto.HexToECDSA(testPrivateKey)
+	assert.NilError(t, err)
+	address := crypto.PubkeyToAddress(key.PublicKey)
+
+	t.Run("unknown account", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{key: key})
+		_, err := signer.NewWeb3Signer(context.Background(), url, common.HexToAddress("0x01"))
+		assert.ErrorContains(t, err, "has no key for")
+	})
+
+	t.Run("sign typed data", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{key: key})
+		s, err := signer.NewWeb3Signer(context.Background(), url, address)
+		assert.NilError(t, err)
+		defer s.Close()
+
+		sig, err := s.SignTypedData(context.Background(), testTypedData())
+		assert.NilError(t, err)
+		assert.Equal(t, len(sig), 65)
+		assert.Assert(t, sig[64] == 27 || sig[64] == 28)
+
+		hash, _, err := apitypes.TypedDataAndHash(testTypedData())
+		assert.NilError(t, err)
+		sig[64] -= 27
+		pubKey, err := crypto.SigToPub(hash, sig)
+		assert.NilError(t, err)
+		assert.Equal(t, crypto.PubkeyToAddress(*pubKey), address)
+	})
+
+	t.Run("sign tx", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{key: key})
+		s, err := signer.NewWeb3Signer(context.Background(), url, address)
+		assert.NilError(t, err)
+		defer s.Close()
+
+		tx := testTx()
+		signedTx, err := s.SignTx(context.Background(), tx, testChainID)
+		assert.NilError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(testChainID), signedTx)
+		assert.NilError(t, err)
+		assert.Equal(t, sender, address)
+		assert.Equal(t, signedTx.Nonce(), tx.Nonce())
+		assert.Equal(t, signedTx.GasFeeCap().Int64(), tx.GasFeeCap().Int64())
+	})
+
+	t.Run("error, signed tx differs from request", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{
+			key:      key,
+			tamperTx: func(tx *types.DynamicFeeTx) { tx.GasFeeCap = big.NewInt(1) },
+		})
+		s, err := signer.NewWeb3Signer(context.Background(), url, address)
+		assert.NilError(t, err)
+		defer s.Close()
+
+		_, err = s.SignTx(context.Background(), testTx(), testChainID)
+		assert.Error(t, err, "web3signer signed a different tx than requested")
+	})
+
+	t.Run("sign legacy and access-list txs", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{key: key})
+		s, err := signer.NewWeb3Signer(context.Background(), url, address)
+		assert.NilError(t, err)
+		defer s.Close()
+
+		to := common.HexToAddress("0x5FC8d32690cc91D4c39d9d3abcBD16989F875707")
+		accessList := types.AccessList{{Address: to, StorageKeys: []common.Hash{common.HexToHash("0x01")}}}
+		for _, tx := range []*types.Transaction{
+			types.NewTx(&types.LegacyTx{Nonce: 7, GasPrice: big.NewInt(1000), Gas: 100000, To: &to}),
+			types.NewTx(&types.AccessListTx{ChainID: testChainID, Nonce: 7, GasPrice: big.NewInt(1000), Gas: 100000, To: &to}),
+			types.NewTx(&types.AccessListTx{
+				ChainID: testChainID, Nonce: 7, GasPrice: big.NewInt(1000), Gas: 100000, To: &to, AccessList: accessList,
+			}),
+		} {
+			signedTx, err := s.SignTx(context.Background(), tx, testChainID)
+			assert.NilError(t, err)
+			assert.Equal(t, signedTx.Type(), tx.Type())
+			assert.Equal(t, len(signedTx.AccessList()), len(tx.AccessList()))
+			sender, err := types.Sender(types.LatestSignerForChainID(testChainID), signedTx)
+			assert.NilError(t, err)
+			assert.Equal(t, sender, address)
+		}
+	})
+
+	t.Run("transact opts", func(t *testing.T) {
+		url := newMockWeb3Signer(t, &mockWeb3Signer{key: key})
+		s, err := signer.NewWeb3Signer(context.Background(), url, address)
+		assert.NilError(t, err)
+		defer s.Close()
+
+		opts := signer.NewTransactOpts(context.Background(), s, testChainID)
+		assert.Equal(t, opts.From, address)
+		_, err = opts.Signer(common.HexToAddress("0x01"), testTx())
+		assert.ErrorContains(t, err, "not authorized")
+		signedTx, err := opts.Signer(address, testTx())
+		assert.NilError(t, err)
+		sender, err := types.Sender(types.LatestSignerForChainID(testChainID), signedTx)
+		assert.NilError(t, err)
+		assert.Equal(t, sender, address)
+	})
+}
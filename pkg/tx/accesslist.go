@@ -0,0 +1,63 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// AccessListCreator is implemented by clients that create the access list of a
+// call, such as with eth_createAccessList.
+type AccessListCreator interface {
+	CreateAccessList(ctx context.Context, msg ethereum.CallMsg) (types.AccessList, error)
+}
+
+// NewAccessListTx returns legacyTx as an access-list (EIP-2930) tx for chainID
+// with accessList, and otherwise the same fields.
+func NewAccessListTx(legacyTx *types.Transaction, chainID *big.Int, accessList types.AccessList) *types.Transaction {
+	return types.NewTx(&types.AccessListTx{
+		ChainID:    chainID,
+		Nonce:      legacyTx.Nonce(),
+		GasPrice:   legacyTx.GasPrice(),
+		Gas:        legacyTx.Gas(),
+		To:         legacyTx.To(),
+		Value:      legacyTx.Value(),
+		Data:       legacyTx.Data(),
+		AccessList: accessList,
+	})
+}
+
+// AccessListSigner returns a signer that turns legacy txs, such as those bind
+// builds from a gas price, into access-list txs for chainID with the access list
+// client creates for them, before signing them with signer. Other txs are signed
+// as they are. The access list only holds state the tx accesses, so it never
+// raises the gas the tx uses.
+func AccessListSigner(
+	ctx context.Context,
+	client AccessListCreator,
+	chainID *big.Int,
+	signer bind.SignerFn,
+) bind.SignerFn {
+	return func(from common.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if tx.Type() != types.LegacyTxType {
+			return signer(from, tx)
+		}
+		accessList, err := client.CreateAccessList(ctx, ethereum.CallMsg{
+			From:     from,
+			To:       tx.To(),
+			Gas:      tx.Gas(),
+			GasPrice: tx.GasPrice(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create access list: %w", err)
+		}
+		return signer(from, NewAccessListTx(tx, chainID, accessList))
+	}
+}
@@ -0,0 +1,105 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/assert"
+)
+
+// mockAccessListCreator returns accessList for every call, recording the calls.
+type mockAccessListCreator struct {
+	accessList types.AccessList
+	err        error
+	calls      []ethereum.CallMsg
+}
+
+func (m *mockAccessListCreator) CreateAccessList(_ context.Context, msg ethereum.CallMsg) (types.AccessList, error) {
+	m.calls = append(m.calls, msg)
+	return m.accessList, m.err
+}
+
+func TestAccessListSigner(t *testing.T) {
+	chainID := big.NewInt(1)
+	to := common.HexToAddress("0x01")
+	accessList := types.AccessList{{
+		Address:     common.HexToAddress("0x02"),
+		StorageKeys: []common.Hash{common.HexToHash("0x03")},
+	}}
+
+	testCases := []struct {
+		name              string
+		tx                *types.Transaction
+		createErr         error
+		expectedType      uint8
+		expectedCalls     int
+		errExpectedOutput string
+	}{
+		{
+			name: "legacy tx",
+			tx: types.NewTx(&types.LegacyTx{
+				Nonce: 4, GasPrice: big.NewInt(1000), Gas: 50000, To: &to, Value: big.NewInt(0), Data: []byte{0xaa},
+			}),
+			expectedType:  types.AccessListTxType,
+			expectedCalls: 1,
+		},
+		{
+			name: "dynamic fee tx",
+			tx: types.NewTx(&types.DynamicFeeTx{
+				ChainID: chainID, Nonce: 4, GasTipCap: big.NewInt(10), GasFeeCap: big.NewInt(1000), Gas: 50000, To: &to,
+			}),
+			expectedType: types.DynamicFeeTxType,
+		},
+		{
+			name: "error, access list not created",
+			tx: types.NewTx(&types.LegacyTx{
+				Nonce: 4, GasPrice: big.NewInt(1000), Gas: 50000, To: &to, Value: big.NewInt(0),
+			}),
+			createErr:         errors.New("method not found"),
+			expectedCalls:     1,
+			errExpectedOutput: "failed to create access list: method not found",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := crypto.GenerateKey()
+			assert.NilError(t, err)
+			from := crypto.PubkeyToAddress(key.PublicKey)
+			opts, err := bind.NewKeyedTransactorWithChainID(key, chainID)
+			assert.NilError(t, err)
+			creator := &mockAccessListCreator{accessList: accessList, err: tc.createErr}
+
+			signer := tx.AccessListSigner(context.Background(), creator, chainID, opts.Signer)
+			signedTx, err := signer(from, tc.tx)
+			assert.Equal(t, tc.expectedCalls, len(creator.calls))
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedType, signedTx.Type())
+			sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+			assert.NilError(t, err)
+			assert.Equal(t, from, sender)
+			assert.Equal(t, tc.tx.Nonce(), signedTx.Nonce())
+			assert.Equal(t, tc.tx.Gas(), signedTx.Gas())
+			assert.Equal(t, tc.tx.GasPrice().String(), signedTx.GasPrice().String())
+			assert.DeepEqual(t, tc.tx.Data(), signedTx.Data())
+			if tc.expectedCalls == 0 {
+				return
+			}
+			assert.DeepEqual(t, accessList, signedTx.AccessList())
+			assert.Equal(t, from, creator.calls[0].From)
+			assert.Equal(t, to, *creator.calls[0].To)
+			assert.Equal(t, "1000", creator.calls[0].GasPrice.String())
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package tx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrFeeCeilingReached is returned when the fee params of a tx would exceed a FeeCeiling.
+var ErrFeeCeilingReached = errors.New("fee ceiling reached")
+
+// CeilingPolicy decides what WaitMinedWithRetry does once boosting a pending tx
+// would exceed its FeeCeiling.
+type CeilingPolicy string
+
+const (
+	// CeilingPolicyStop stops retrying and reports the last pending tx.
+	CeilingPolicyStop CeilingPolicy = "stop"
+	// CeilingPolicyWait keeps waiting for the last pending tx without boosting it further.
+	CeilingPolicyWait CeilingPolicy = "wait"
+)
+
+// FeeCeiling bounds the fee params of every tx sent by a command, and the total
+// spent on gas by all of them. Nil limits are not enforced. A nil *FeeCeiling
+// enforces nothing. It is the only place the max fee limits are enforced: gas
+// strategies never clamp fees to them, so fees past a limit are refused instead.
+type FeeCeiling struct {
+	MaxGasFeeCap  *big.Int
+	MaxGasTipCap  *big.Int
+	MaxTotalSpend *big.Int
+	Policy        CeilingPolicy
+
+	mu    sync.Mutex
+	spent big.Int
+}
+
+// Check returns ErrFeeCeilingReached if a tx with the given fee params exceeds
+// the fee cap or tip ceilings, or could cost more than what remains of the budget.
+func (c *FeeCeiling) Check(gasTip, gasFeeCap *big.Int, gasLimit uint64) error {
+	if c == nil {
+		return nil
+	}
+	if c.MaxGasFeeCap != nil && gasFeeCap.Cmp(c.MaxGasFeeCap) > 0 {
+		return fmt.Errorf("%w: gas fee cap %s exceeds max %s", ErrFeeCeilingReached, gasFeeCap, c.MaxGasFeeCap)
+	}
+	if c.MaxGasTipCap != nil && gasTip.Cmp(c.MaxGasTipCap) > 0 {
+		return fmt.Errorf("%w: gas tip cap %s exceeds max %s", ErrFeeCeilingReached, gasTip, c.MaxGasTipCap)
+	}
+	if c.MaxTotalSpend != nil {
+		maxCost := new(big.Int).Mul(gasFeeCap, new(big.Int).SetUint64(gasLimit))
+		remaining := new(big.Int).Sub(c.MaxTotalSpend, c.Spent())
+		if maxCost.Cmp(remaining) > 0 {
+			return fmt.Errorf("%w: tx may cost up to %s wei but only %s wei of the %s wei budget remain",
+				ErrFeeCeilingReached, maxCost, remaining, c.MaxTotalSpend)
+		}
+	}
+	return nil
+}
+
+// RecordReceipt adds the gas paid for a mined tx to the total spent.
+func (c *FeeCeiling) RecordReceipt(receipt *types.Receipt) {
+	if c == nil || receipt == nil || receipt.EffectiveGasPrice == nil {
+		return
+	}
+	cost := new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed))
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.spent.Add(&c.spent, cost)
+}
+
+// Spent returns the total gas paid for txs recorded so far, in wei.
+func (c *FeeCeiling) Spent() *big.Int {
+	if c == nil {
+		return new(big.Int)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return new(big.Int).Set(&c.spent)
+}
+
+func (c *FeeCeiling) policy() CeilingPolicy {
+	if c == nil || c.Policy == "" {
+		return CeilingPolicyStop
+	}
+	return c.Policy
+}
@@ -0,0 +1,85 @@
+package tx_test
+
+import (
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/assert"
+)
+
+func TestFeeCeiling(t *testing.T) {
+	testCases := []struct {
+		name              string
+		ceiling           *tx.FeeCeiling
+		spent             *types.Receipt
+		gasTip            int64
+		gasFeeCap         int64
+		gasLimit          uint64
+		errExpectedOutput string
+	}{
+		{
+			name:      "no ceiling",
+			gasTip:    100,
+			gasFeeCap: 1000,
+			gasLimit:  21000,
+		},
+		{
+			name:      "within ceiling",
+			ceiling:   &tx.FeeCeiling{MaxGasFeeCap: big.NewInt(1000), MaxGasTipCap: big.NewInt(100)},
+			gasTip:    100,
+			gasFeeCap: 1000,
+			gasLimit:  21000,
+		},
+		{
+			name:              "error, gas fee cap above ceiling",
+			ceiling:           &tx.FeeCeiling{MaxGasFeeCap: big.NewInt(1000)},
+			gasTip:            100,
+			gasFeeCap:         1001,
+			gasLimit:          21000,
+			errExpectedOutput: "fee ceiling reached: gas fee cap 1001 exceeds max 1000",
+		},
+		{
+			name:              "error, gas tip cap above ceiling",
+			ceiling:           &tx.FeeCeiling{MaxGasTipCap: big.NewInt(100)},
+			gasTip:            101,
+			gasFeeCap:         1000,
+			gasLimit:          21000,
+			errExpectedOutput: "fee ceiling reached: gas tip cap 101 exceeds max 100",
+		},
+		{
+			name:      "within budget",
+			ceiling:   &tx.FeeCeiling{MaxTotalSpend: big.NewInt(21_000_000)},
+			gasTip:    100,
+			gasFeeCap: 1000,
+			gasLimit:  21000,
+		},
+		{
+			name:    "error, budget spent by earlier tx",
+			ceiling: &tx.FeeCeiling{MaxTotalSpend: big.NewInt(21_000_000)},
+			spent: &types.Receipt{
+				GasUsed:           21000,
+				EffectiveGasPrice: big.NewInt(1),
+			},
+			gasTip:    100,
+			gasFeeCap: 1000,
+			gasLimit:  21000,
+			errExpectedOutput: "fee ceiling reached: tx may cost up to 21000000 wei " +
+				"but only 20979000 wei of the 21000000 wei budget remain",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.ceiling.RecordReceipt(tc.spent)
+			err := tc.ceiling.Check(big.NewInt(tc.gasTip), big.NewInt(tc.gasFeeCap), tc.gasLimit)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				assert.Assert(t, errors.Is(err, tx.ErrFeeCeilingReached))
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
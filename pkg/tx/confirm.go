@@ -0,0 +1,120 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrReorged is returned when a mined tx is no longer part of the canonical chain.
+var ErrReorged = errors.New("tx reorged out of the canonical chain")
+
+// WaitConfirmed waits until the tx of receipt is in a block that is confirmations
+// blocks deep, counting the block itself, and returns its receipt from the
+// canonical chain. If the tx moves to another block, the receipt of that block is
+// followed instead. ErrReorged is returned if the tx is no longer mined at all.
+func WaitConfirmed(
+	ctx context.Context,
+	client EthClient,
+	receipt *types.Receipt,
+	confirmations uint64,
+	logger *slog.Logger,
+) (*types.Receipt, error) {
+	if confirmations <= 1 {
+		return receipt, nil
+	}
+
+	ticker := time.NewTicker(blockPollInterval)
+	defer ticker.Stop()
+	for {
+		current, err := client.TransactionReceipt(ctx, receipt.TxHash)
+		switch {
+		case errors.Is(err, ethereum.NotFound):
+			// A missing receipt may come from an endpoint lagging behind the one that
+			// returned it, only a replaced block means the tx was reorged out.
+			canonical, known, err := canonicalHash(ctx, client, receipt.BlockNumber)
+			if err != nil {
+				return nil, err
+			}
+			if known && canonical != receipt.BlockHash {
+				return nil, fmt.Errorf("%w: tx %s was mined in block %s", ErrReorged, receipt.TxHash.Hex(), receipt.BlockHash.Hex())
+			}
+			logger.Debug("tx receipt not found, waiting for the node to catch up",
+				"txHash", receipt.TxHash.Hex(), "block", receipt.BlockNumber)
+		case err != nil:
+			return nil, fmt.Errorf("failed to get tx receipt: %w", err)
+		default:
+			if current.BlockHash != receipt.BlockHash {
+				logger.Warn("tx moved to another block after a reorg",
+					"txHash", receipt.TxHash.Hex(),
+					"oldBlock", receipt.BlockHash.Hex(),
+					"newBlock", current.BlockHash.Hex(),
+				)
+				receipt = current
+			}
+			confirmed, err := isConfirmed(ctx, client, receipt, confirmations, logger)
+			if err != nil {
+				return nil, err
+			}
+			if confirmed {
+				return receipt, nil
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// isConfirmed reports whether the block of receipt is canonical and confirmations
+// blocks deep.
+func isConfirmed(
+	ctx context.Context,
+	client EthClient,
+	receipt *types.Receipt,
+	confirmations uint64,
+	logger *slog.Logger,
+) (bool, error) {
+	head, err := client.BlockNumber(ctx)
+	if err != nil {
+		return false, fmt.Errorf("failed to get block number: %w", err)
+	}
+	depth := uint64(0)
+	if mined := receipt.BlockNumber.Uint64(); head >= mined {
+		depth = head - mined + 1
+	}
+	logger.Debug("waiting for confirmations",
+		"txHash", receipt.TxHash.Hex(), "confirmations", depth, "required", confirmations)
+	if depth < confirmations {
+		return false, nil
+	}
+	// The node may index the receipt before switching its head, check again later.
+	canonical, known, err := canonicalHash(ctx, client, receipt.BlockNumber)
+	if err != nil {
+		return false, err
+	}
+	return known && canonical == receipt.BlockHash, nil
+}
+
+// canonicalHash returns the hash of the canonical block at number, and false if
+// the node doesn't have that block yet.
+func canonicalHash(ctx context.Context, client EthClient, number *big.Int) (common.Hash, bool, error) {
+	header, err := client.HeaderByNumber(ctx, number)
+	switch {
+	case errors.Is(err, ethereum.NotFound):
+		return common.Hash{}, false, nil
+	case err != nil:
+		return common.Hash{}, false, fmt.Errorf("failed to get header of block %s: %w", number, err)
+	}
+	return header.Hash(), true, nil
+}
@@ -0,0 +1,146 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"gotest.tools/assert"
+)
+
+// confirmMockEthClient serves a fixed head and receipt. The receipt is missing for
+// the first missingReceipts reads, and both the receipt and the headers for the
+// first lagReads reads, as on an endpoint lagging behind.
+type confirmMockEthClient struct {
+	*MockEthClient
+	head            uint64
+	receipt         *types.Receipt
+	headers         map[uint64]*types.Header
+	missingReceipts int
+	lagReads        int
+}
+
+func (m *confirmMockEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if m.missingReceipts > 0 {
+		m.missingReceipts--
+		return nil, ethereum.NotFound
+	}
+	if m.receipt == nil || m.lagReads > 0 {
+		return nil, ethereum.NotFound
+	}
+	return m.receipt, nil
+}
+
+func (m *confirmMockEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return m.head, nil
+}
+
+func (m *confirmMockEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	if m.lagReads > 0 {
+		m.lagReads--
+		return nil, ethereum.NotFound
+	}
+	header, ok := m.headers[number.Uint64()]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return header, nil
+}
+
+func TestWaitConfirmed(t *testing.T) {
+	header := func(number uint64, extra string) *types.Header {
+		return &types.Header{Number: new(big.Int).SetUint64(number), Extra: []byte(extra)}
+	}
+	receiptIn := func(h *types.Header) *types.Receipt {
+		return &types.Receipt{
+			TxHash:      common.HexToHash("0x01"),
+			BlockHash:   h.Hash(),
+			BlockNumber: h.Number,
+		}
+	}
+	mined := header(100, "a")
+	reorgedTo := header(101, "b")
+
+	testCases := []struct {
+		name            string
+		confirmations   uint64
+		client          *confirmMockEthClient
+		expectedReceipt *types.Receipt
+		expectedErr     error
+	}{
+		{
+			name:            "no confirmations required",
+			confirmations:   1,
+			client:          &confirmMockEthClient{},
+			expectedReceipt: receiptIn(mined),
+		},
+		{
+			name:          "deep enough",
+			confirmations: 3,
+			client: &confirmMockEthClient{
+				head:    102,
+				receipt: receiptIn(mined),
+				headers: map[uint64]*types.Header{100: mined},
+			},
+			expectedReceipt: receiptIn(mined),
+		},
+		{
+			name:          "moved to another block deep enough",
+			confirmations: 2,
+			client: &confirmMockEthClient{
+				head:    102,
+				receipt: receiptIn(reorgedTo),
+				headers: map[uint64]*types.Header{101: reorgedTo},
+			},
+			expectedReceipt: receiptIn(reorgedTo),
+		},
+		{
+			name:          "receipt missing while its block is canonical",
+			confirmations: 3,
+			client: &confirmMockEthClient{
+				head:            102,
+				receipt:         receiptIn(mined),
+				headers:         map[uint64]*types.Header{100: mined},
+				missingReceipts: 1,
+			},
+			expectedReceipt: receiptIn(mined),
+		},
+		{
+			name:          "endpoint lagging behind the block",
+			confirmations: 3,
+			client: &confirmMockEthClient{
+				head:     102,
+				receipt:  receiptIn(mined),
+				headers:  map[uint64]*types.Header{100: mined},
+				lagReads: 1,
+			},
+			expectedReceipt: receiptIn(mined),
+		},
+		{
+			name:          "error, reorged out",
+			confirmations: 3,
+			client: &confirmMockEthClient{
+				head:    102,
+				headers: map[uint64]*types.Header{100: header(100, "c")},
+			},
+			expectedErr: tx.ErrReorged,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			receipt, err := tx.WaitConfirmed(context.Background(), tc.client, receiptIn(mined), tc.confirmations, slog.Default())
+			if tc.expectedErr != nil {
+				assert.Assert(t, errors.Is(err, tc.expectedErr))
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedReceipt.BlockHash, receipt.BlockHash)
+		})
+	}
+}
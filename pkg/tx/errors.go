@@ -0,0 +1,118 @@
+package tx
+
+import (
+	"errors"
+	"strings"
+)
+
+// Typed tx submission errors. ClassifyError maps the errors returned by execution
+// clients to these, so callers can use errors.Is regardless of the client.
+var (
+	ErrNonceTooLow        = errors.New("nonce too low")
+	ErrAlreadyKnown       = errors.New("tx already known")
+	ErrUnderpriced        = errors.New("tx underpriced")
+	ErrInsufficientFunds  = errors.New("insufficient funds for gas * price + value")
+	ErrIntrinsicGasTooLow = errors.New("intrinsic gas too low")
+	ErrFeeCapBelowBaseFee = errors.New("fee cap less than block base fee")
+	ErrTxPoolFull         = errors.New("txpool is full")
+)
+
+// rpcErrorPatterns maps the error messages and codes of geth, Erigon, Nethermind,
+// Besu and Reth to typed errors. Patterns are matched against the error message
+// lowercased and stripped of spaces, underscores and dashes, so "NONCE_TOO_LOW"
+// and "nonce too low" match alike. More specific patterns come first.
+var rpcErrorPatterns = []struct {
+	pattern string
+	kind    error
+}{
+	// geth, Erigon, Reth: "nonce too low". Besu: NONCE_TOO_LOW. Nethermind: OldNonce.
+	{"noncetoolow", ErrNonceTooLow},
+	{"oldnonce", ErrNonceTooLow},
+	// geth, Erigon, Reth: "already known". Besu: TRANSACTION_ALREADY_KNOWN.
+	// Nethermind: AlreadyKnown. Older geth: "known transaction".
+	{"alreadyknown", ErrAlreadyKnown},
+	{"knowntransaction", ErrAlreadyKnown},
+	{"alreadyimported", ErrAlreadyKnown},
+	// geth, Reth: "max fee per gas less than block base fee". Besu:
+	// GAS_PRICE_BELOW_CURRENT_BASE_FEE. Erigon: "fee cap less than block base fee".
+	{"maxfeepergaslessthanblockbasefee", ErrFeeCapBelowBaseFee},
+	{"feecaplessthanblockbasefee", ErrFeeCapBelowBaseFee},
+	{"gaspricebelowcurrentbasefee", ErrFeeCapBelowBaseFee},
+	{"feecapbelowbasefee", ErrFeeCapBelowBaseFee},
+	// geth, Erigon, Reth: "(replacement) transaction underpriced". Besu:
+	// REPLACEMENT_UNDERPRICED, GAS_PRICE_TOO_LOW. Nethermind: FeeTooLow,
+	// FeeTooLowToCompete.
+	{"underpriced", ErrUnderpriced},
+	{"gaspricetoolow", ErrUnderpriced},
+	{"feetoolow", ErrUnderpriced},
+	// geth, Erigon, Reth: "insufficient funds for gas * price + value". Besu:
+	// UPFRONT_COST_EXCEEDS_BALANCE. Nethermind: InsufficientFunds.
+	{"insufficientfunds", ErrInsufficientFunds},
+	{"upfrontcostexceedsbalance", ErrInsufficientFunds},
+	// geth, Erigon, Reth: "intrinsic gas too low". Besu: INTRINSIC_GAS_EXCEEDS_GAS_LIMIT.
+	{"intrinsicgastoolow", ErrIntrinsicGasTooLow},
+	{"intrinsicgasexceedsgaslimit", ErrIntrinsicGasTooLow},
+	{"intrinsicgas", ErrIntrinsicGasTooLow},
+	// geth, Reth: "txpool is full". Erigon: "pool overflow". Nethermind reports a
+	// full pool as FeeTooLowToCompete, classified as underpriced above.
+	{"txpoolisfull", ErrTxPoolFull},
+	{"txpoolfull", ErrTxPoolFull},
+	{"transactionpoolisfull", ErrTxPoolFull},
+	{"pooloverflow", ErrTxPoolFull},
+}
+
+// classifiedError keeps the original error of a classified one, so both the
+// typed error and the client's error can be matched with errors.Is and errors.As.
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string { return e.err.Error() }
+
+func (e *classifiedError) Unwrap() []error { return []error{e.kind, e.err} }
+
+// ClassifyError wraps an error returned by an execution client on tx submission
+// with the typed error it corresponds to. Unrecognized errors are returned unchanged.
+func ClassifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if kind := errorKind(err); kind != nil {
+		return &classifiedError{kind: kind, err: err}
+	}
+	return err
+}
+
+func errorKind(err error) error {
+	for _, kind := range []error{
+		ErrNonceTooLow,
+		ErrAlreadyKnown,
+		ErrUnderpriced,
+		ErrInsufficientFunds,
+		ErrIntrinsicGasTooLow,
+		ErrFeeCapBelowBaseFee,
+		ErrTxPoolFull,
+	} {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+	msg := strings.NewReplacer(" ", "", "_", "", "-", "").Replace(strings.ToLower(err.Error()))
+	for _, p := range rpcErrorPatterns {
+		if strings.Contains(msg, p.pattern) {
+			return p.kind
+		}
+	}
+	return nil
+}
+
+// IsRetryable reports whether a tx submission error may go away by waiting or
+// boosting fees and resubmitting.
+func IsRetryable(err error) bool {
+	switch errorKind(err) {
+	case ErrAlreadyKnown, ErrUnderpriced, ErrFeeCapBelowBaseFee, ErrTxPoolFull:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package tx_test
+
+import (
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"fmt"
+	"testing"
+
+	"gotest.tools/assert"
+)
+
+func TestClassifyError(t *testing.T) {
+	testCases := []struct {
+		name              string
+		err               error
+		expectedKind      error
+		expectedRetryable bool
+	}{
+		{
+			name:         "geth nonce too low",
+			err:          errors.New("nonce too low: next nonce 5, tx nonce 4"),
+			expectedKind: tx.ErrNonceTooLow,
+		},
+		{
+			name:         "nethermind old nonce",
+			err:          errors.New("OldNonce"),
+			expectedKind: tx.ErrNonceTooLow,
+		},
+		{
+			name:              "geth replacement underpriced",
+			err:               errors.New("replacement transaction underpriced"),
+			expectedKind:      tx.ErrUnderpriced,
+			expectedRetryable: true,
+		},
+		{
+			name:              "besu replacement underpriced",
+			err:               errors.New("REPLACEMENT_UNDERPRICED"),
+			expectedKind:      tx.ErrUnderpriced,
+			expectedRetryable: true,
+		},
+		{
+			name:              "nethermind fee too low to compete",
+			err:               errors.New("FeeTooLowToCompete"),
+			expectedKind:      tx.ErrUnderpriced,
+			expectedRetryable: true,
+		},
+		{
+			name:              "reth already known",
+			err:               errors.New("already known"),
+			expectedKind:      tx.ErrAlreadyKnown,
+			expectedRetryable: true,
+		},
+		{
+			name:              "besu already known",
+			err:               errors.New("TRANSACTION_ALREADY_KNOWN"),
+			expectedKind:      tx.ErrAlreadyKnown,
+			expectedRetryable: true,
+		},
+		{
+			name:         "geth insufficient funds",
+			err:          errors.New("insufficient funds for gas * price + value: balance 0, tx cost 21000"),
+			expectedKind: tx.ErrInsufficientFunds,
+		},
+		{
+			name:         "besu insufficient funds",
+			err:          errors.New("UPFRONT_COST_EXCEEDS_BALANCE"),
+			expectedKind: tx.ErrInsufficientFunds,
+		},
+		{
+			name:         "geth intrinsic gas too low",
+			err:          errors.New("intrinsic gas too low: have 0, want 21000"),
+			expectedKind: tx.ErrIntrinsicGasTooLow,
+		},
+		{
+			name:              "geth fee cap below base fee",
+			err:               errors.New("max fee per gas less than block base fee: address 0x01, maxFeePerGas: 1, baseFee: 7"),
+			expectedKind:      tx.ErrFeeCapBelowBaseFee,
+			expectedRetryable: true,
+		},
+		{
+			name:              "besu fee cap below base fee",
+			err:               errors.New("GAS_PRICE_BELOW_CURRENT_BASE_FEE"),
+			expectedKind:      tx.ErrFeeCapBelowBaseFee,
+			expectedRetryable: true,
+		},
+		{
+			name:              "erigon pool overflow",
+			err:               errors.New("pool overflow"),
+			expectedKind:      tx.ErrTxPoolFull,
+			expectedRetryable: true,
+		},
+		{
+			name:              "wrapped",
+			err:               fmt.Errorf("failed to send tx: %w", errors.New("txpool is full")),
+			expectedKind:      tx.ErrTxPoolFull,
+			expectedRetryable: true,
+		},
+		{
+			name: "unknown",
+			err:  errors.New("connection refused"),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tx.ClassifyError(tc.err)
+			assert.Assert(t, errors.Is(err, tc.err))
+			assert.Equal(t, err.Error(), tc.err.Error())
+			if tc.expectedKind != nil {
+				assert.Assert(t, errors.Is(err, tc.expectedKind))
+			} else {
+				assert.Equal(t, err, tc.err)
+			}
+			assert.Equal(t, tc.expectedRetryable, tx.IsRetryable(err))
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"slices"
+)
+
+// GasStrategy decides the fee params of a tx and how to raise them when a tx has
+// to be resubmitted. EIP-1559 txs are priced with SuggestFees and BoostFees, and
+// legacy txs on chains without EIP-1559 with SuggestGasPrice and BoostGasPrice.
+type GasStrategy interface {
+	// SuggestFees returns the gas tip cap and gas fee cap for a new tx.
+	SuggestFees(ctx context.Context, client EthClient) (gasTip *big.Int, gasFeeCap *big.Int, err error)
+	// BoostFees returns fee params raised by percent for replacing a tx priced at
+	// gasTip and gasFeeCap.
+	BoostFees(
+		ctx context.Context,
+		client EthClient,
+		gasTip, gasFeeCap *big.Int,
+		percent uint64,
+	) (*big.Int, *big.Int, error)
+	// SuggestGasPrice returns the gas price for a new legacy tx.
+	SuggestGasPrice(ctx context.Context, client EthClient) (*big.Int, error)
+	// BoostGasPrice returns a gas price raised by percent for replacing a legacy
+	// tx priced at gasPrice.
+	BoostGasPrice(ctx context.Context, client EthClient, gasPrice *big.Int, percent uint64) (*big.Int, error)
+}
+
+// NodeGasStrategy trusts the node's SuggestGasTipCap and SuggestGasPrice.
+type NodeGasStrategy struct{}
+
+func (NodeGasStrategy) SuggestFees(ctx context.Context, client EthClient) (*big.Int, *big.Int, error) {
+	return SuggestGasTipCapAndPrice(ctx, client)
+}
+
+func (s NodeGasStrategy) BoostFees(
+	ctx context.Context,
+	client EthClient,
+	gasTip, gasFeeCap *big.Int,
+	percent uint64,
+) (*big.Int, *big.Int, error) {
+	return boostWithSuggestion(ctx, client, s, gasTip, gasFeeCap, percent)
+}
+
+func (NodeGasStrategy) SuggestGasPrice(ctx context.Context, client EthClient) (*big.Int, error) {
+	gasPrice, err := client.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get gas price: %w", err)
+	}
+	return gasPrice, nil
+}
+
+func (s NodeGasStrategy) BoostGasPrice(
+	ctx context.Context,
+	client EthClient,
+	gasPrice *big.Int,
+	percent uint64,
+) (*big.Int, error) {
+	return boostPriceWithSuggestion(ctx, client, s, gasPrice, percent)
+}
+
+// FeeHistoryGasStrategy prices txs from eth_feeHistory over the last Blocks blocks.
+// The tip is the median across blocks of the Percentile-th percentile of the
+// priority fees paid in each block, and the fee cap leaves room for the base fee
+// to double. On chains without EIP-1559 base fees are zero and priority fees are
+// the full gas price, so legacy txs are priced at the same percentile.
+type FeeHistoryGasStrategy struct {
+	Blocks     uint64
+	Percentile float64
+}
+
+func (s FeeHistoryGasStrategy) SuggestFees(ctx context.Context, client EthClient) (*big.Int, *big.Int, error) {
+	if s.Blocks == 0 {
+		return nil, nil, fmt.Errorf("fee history block count must be positive")
+	}
+	if s.Percentile < 0 || s.Percentile > 100 {
+		return nil, nil, fmt.Errorf("fee history percentile must be between 0 and 100: %v", s.Percentile)
+	}
+	history, err := client.FeeHistory(ctx, s.Blocks, nil, []float64{s.Percentile})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get fee history: %w", err)
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, nil, fmt.Errorf("fee history has no base fees")
+	}
+
+	var tips []*big.Int
+	for _, reward := range history.Reward {
+		if len(reward) > 0 && reward[0] != nil {
+			tips = append(tips, reward[0])
+		}
+	}
+	gasTip := big.NewInt(0)
+	if len(tips) > 0 {
+		slices.SortFunc(tips, func(a, b *big.Int) int { return a.Cmp(b) })
+		gasTip = new(big.Int).Set(tips[len(tips)/2])
+	}
+
+	// The last base fee is that of the next block.
+	nextBaseFee := history.BaseFee[len(history.BaseFee)-1]
+	gasFeeCap := new(big.Int).Add(new(big.Int).Mul(nextBaseFee, big.NewInt(2)), gasTip)
+	return gasTip, gasFeeCap, nil
+}
+
+func (s FeeHistoryGasStrategy) BoostFees(
+	ctx context.Context,
+	client EthClient,
+	gasTip, gasFeeCap *big.Int,
+	percent uint64,
+) (*big.Int, *big.Int, error) {
+	return boostWithSuggestion(ctx, client, s, gasTip, gasFeeCap, percent)
+}
+
+func (s FeeHistoryGasStrategy) SuggestGasPrice(ctx context.Context, client EthClient) (*big.Int, error) {
+	_, gasFeeCap, err := s.SuggestFees(ctx, client)
+	return gasFeeCap, err
+}
+
+func (s FeeHistoryGasStrategy) BoostGasPrice(
+	ctx context.Context,
+	client EthClient,
+	gasPrice *big.Int,
+	percent uint64,
+) (*big.Int, error) {
+	return boostPriceWithSuggestion(ctx, client, s, gasPrice, percent)
+}
+
+// FixedGasStrategy always uses the same fee params, and GasFeeCap as the gas price
+// of legacy txs. Boosting raises them by the same margin as the other strategies,
+// since nodes reject replacements that don't.
+type FixedGasStrategy struct {
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+func (s FixedGasStrategy) SuggestFees(context.Context, EthClient) (*big.Int, *big.Int, error) {
+	if s.GasTipCap == nil || s.GasFeeCap == nil {
+		return nil, nil, fmt.Errorf("fixed gas tip cap and gas fee cap must be set")
+	}
+	if s.GasTipCap.Cmp(s.GasFeeCap) > 0 {
+		return nil, nil, fmt.Errorf("fixed gas tip cap %s exceeds gas fee cap %s", s.GasTipCap, s.GasFeeCap)
+	}
+	return new(big.Int).Set(s.GasTipCap), new(big.Int).Set(s.GasFeeCap), nil
+}
+
+func (s FixedGasStrategy) BoostFees(
+	_ context.Context,
+	_ EthClient,
+	gasTip, gasFeeCap *big.Int,
+	percent uint64,
+) (*big.Int, *big.Int, error) {
+	boostedTip, boostedFeeCap := boostFees(gasTip, gasFeeCap, gasTip, gasFeeCap, percent)
+	return boostedTip, boostedFeeCap, nil
+}
+
+func (s FixedGasStrategy) SuggestGasPrice(ctx context.Context, client EthClient) (*big.Int, error) {
+	_, gasFeeCap, err := s.SuggestFees(ctx, client)
+	return gasFeeCap, err
+}
+
+func (s FixedGasStrategy) BoostGasPrice(
+	_ context.Context,
+	_ EthClient,
+	gasPrice *big.Int,
+	percent uint64,
+) (*big.Int, error) {
+	return boostGasPrice(gasPrice, gasPrice, percent), nil
+}
+
+// boostWithSuggestion boosts fees above both the previous fees and the strategy's
+// current suggestion.
+func boostWithSuggestion(
+	ctx context.Context,
+	client EthClient,
+	strategy GasStrategy,
+	gasTip, gasFeeCap *big.Int,
+	percent uint64,
+) (*big.Int, *big.Int, error) {
+	newGasTip, newFeeCap, err := strategy.SuggestFees(ctx, client)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to suggest gas tip cap and price: %w", err)
+	}
+	if newBaseFee := new(big.Int).Sub(newFeeCap, newGasTip); newBaseFee.Sign() < 0 {
+		return nil, nil, fmt.Errorf("new base fee cannot be negative: %s", newBaseFee.String())
+	}
+	boostedTip, boostedFeeCap := boostFees(newGasTip, newFeeCap, gasTip, gasFeeCap, percent)
+	return boostedTip, boostedFeeCap, nil
+}
+
+// boostFees raises the higher of the new and previous tip by just above percent,
+// and the higher of the new and previous base fee by percent.
+func boostFees(newGasTip, newFeeCap, prevGasTip, prevFeeCap *big.Int, percent uint64) (*big.Int, *big.Int) {
+	maxBaseFee := new(big.Int).Sub(newFeeCap, newGasTip)
+	if prevBaseFee := new(big.Int).Sub(prevFeeCap, prevGasTip); prevBaseFee.Cmp(maxBaseFee) > 0 {
+		maxBaseFee = prevBaseFee
+	}
+	maxGasTip := newGasTip
+	if prevGasTip.Cmp(maxGasTip) > 0 {
+		maxGasTip = prevGasTip
+	}
+
+	boostedTip := addPercentTo(maxGasTip, percent)
+	boostedTip = boostedTip.Add(boostedTip, big.NewInt(1))
+	boostedBaseFee := addPercentTo(maxBaseFee, percent)
+	return boostedTip, new(big.Int).Add(boostedBaseFee, boostedTip)
+}
+
+// boostPriceWithSuggestion boosts a legacy gas price above both the previous price
+// and the strategy's current suggestion.
+func boostPriceWithSuggestion(
+	ctx context.Context,
+	client EthClient,
+	strategy GasStrategy,
+	gasPrice *big.Int,
+	percent uint64,
+) (*big.Int, error) {
+	newGasPrice, err := strategy.SuggestGasPrice(ctx, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to suggest gas price: %w", err)
+	}
+	return boostGasPrice(newGasPrice, gasPrice, percent), nil
+}
+
+// boostGasPrice raises the higher of the new and previous gas price by just above percent.
+func boostGasPrice(newGasPrice, prevGasPrice *big.Int, percent uint64) *big.Int {
+	maxGasPrice := newGasPrice
+	if prevGasPrice.Cmp(maxGasPrice) > 0 {
+		maxGasPrice = prevGasPrice
+	}
+	boosted := addPercentTo(maxGasPrice, percent)
+	return boosted.Add(boosted, big.NewInt(1))
+}
@@ -0,0 +1,117 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"gotest.tools/assert"
+)
+
+func TestGasStrategies(t *testing.T) {
+	node := NewMockEthClient(
+		func() (*big.Int, error) { return big.NewInt(100), nil },
+		func() (*big.Int, error) { return big.NewInt(1000), nil },
+	)
+	node.feeHistory = &ethereum.FeeHistory{
+		Reward:  [][]*big.Int{{big.NewInt(30)}, {big.NewInt(10)}, {big.NewInt(20)}},
+		BaseFee: []*big.Int{big.NewInt(400), big.NewInt(450), big.NewInt(500), big.NewInt(550)},
+	}
+
+	testCases := []struct {
+		name              string
+		strategy          tx.GasStrategy
+		expectedGasTip    int64
+		expectedGasFeeCap int64
+		errExpectedOutput string
+	}{
+		{
+			name:              "node",
+			strategy:          tx.NodeGasStrategy{},
+			expectedGasTip:    100,
+			expectedGasFeeCap: 1000,
+		},
+		{
+			name:              "fee history",
+			strategy:          tx.FeeHistoryGasStrategy{Blocks: 3, Percentile: 50},
+			expectedGasTip:    20,   // median reward
+			expectedGasFeeCap: 1120, // 2 * next base fee + tip
+		},
+		{
+			name:              "error, fee history percentile out of range",
+			strategy:          tx.FeeHistoryGasStrategy{Blocks: 3, Percentile: 101},
+			errExpectedOutput: "fee history percentile must be between 0 and 100: 101",
+		},
+		{
+			name:              "fixed",
+			strategy:          tx.FixedGasStrategy{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(50)},
+			expectedGasTip:    5,
+			expectedGasFeeCap: 50,
+		},
+		{
+			name:              "error, fixed tip above fee cap",
+			strategy:          tx.FixedGasStrategy{GasTipCap: big.NewInt(60), GasFeeCap: big.NewInt(50)},
+			errExpectedOutput: "fixed gas tip cap 60 exceeds gas fee cap 50",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gasTip, gasFeeCap, err := tc.strategy.SuggestFees(context.Background(), node)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, gasTip.Int64(), tc.expectedGasTip)
+			assert.Equal(t, gasFeeCap.Int64(), tc.expectedGasFeeCap)
+		})
+	}
+}
+
+func TestLegacyGasStrategies(t *testing.T) {
+	node := NewMockEthClient(nil, func() (*big.Int, error) { return big.NewInt(1000), nil })
+	// Chains without EIP-1559 report zero base fees and the full gas price as reward.
+	node.feeHistory = &ethereum.FeeHistory{
+		Reward:  [][]*big.Int{{big.NewInt(900)}, {big.NewInt(700)}, {big.NewInt(800)}},
+		BaseFee: []*big.Int{big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0)},
+	}
+
+	testCases := []struct {
+		name                  string
+		strategy              tx.GasStrategy
+		expectedGasPrice      int64
+		expectedBoostGasPrice int64
+	}{
+		{
+			name:                  "node",
+			strategy:              tx.NodeGasStrategy{},
+			expectedGasPrice:      1000,
+			expectedBoostGasPrice: 1101, // 1.1 * suggestion + 1
+		},
+		{
+			name:                  "fee history",
+			strategy:              tx.FeeHistoryGasStrategy{Blocks: 3, Percentile: 50},
+			expectedGasPrice:      800, // median reward
+			expectedBoostGasPrice: 1101,
+		},
+		{
+			name:                  "fixed",
+			strategy:              tx.FixedGasStrategy{GasTipCap: big.NewInt(5), GasFeeCap: big.NewInt(50)},
+			expectedGasPrice:      50,
+			expectedBoostGasPrice: 1101,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gasPrice, err := tc.strategy.SuggestGasPrice(context.Background(), node)
+			assert.NilError(t, err)
+			assert.Equal(t, gasPrice.Int64(), tc.expectedGasPrice)
+
+			boosted, err := tc.strategy.BoostGasPrice(context.Background(), node, big.NewInt(1000), 10)
+			assert.NilError(t, err)
+			assert.Equal(t, boosted.Int64(), tc.expectedBoostGasPrice)
+		})
+	}
+}
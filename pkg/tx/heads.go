@@ -0,0 +1,88 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// HeadSubscriber is implemented by clients that push new chain heads, such as
+// ethclient.Client connected over WebSocket or IPC. Over HTTP, subscribing fails.
+type HeadSubscriber interface {
+	SubscribeNewHead(ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error)
+}
+
+// WaitForBlock blocks until the chain head reaches block target. With subscribe
+// set, new heads are followed through a header subscription if client supports
+// one. Otherwise, or once the subscription fails, the block number is polled.
+func WaitForBlock(
+	ctx context.Context,
+	client EthClient,
+	target uint64,
+	subscribe bool,
+	logger *slog.Logger,
+) error {
+	if subscribe {
+		err := waitForBlockHeads(ctx, client, target)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		logger.Debug("header subscription unavailable, polling block number", "error", err)
+	}
+	return pollForBlock(ctx, client, target)
+}
+
+func waitForBlockHeads(ctx context.Context, client EthClient, target uint64) error {
+	subscriber, ok := client.(HeadSubscriber)
+	if !ok {
+		return errors.New("client doesn't support header subscriptions")
+	}
+	heads := make(chan *types.Header)
+	sub, err := subscriber.SubscribeNewHead(ctx, heads)
+	if err != nil {
+		return fmt.Errorf("failed to subscribe to new heads: %w", err)
+	}
+	defer sub.Unsubscribe()
+
+	// Heads from before the subscription are not delivered, so check the current one.
+	blockNum, err := client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get block number: %w", err)
+	}
+	if blockNum >= target {
+		return nil
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case err := <-sub.Err():
+			return fmt.Errorf("header subscription ended: %v", err)
+		case head := <-heads:
+			if head.Number.Uint64() >= target {
+				return nil
+			}
+		}
+	}
+}
+
+func pollForBlock(ctx context.Context, client EthClient, target uint64) error {
+	ticker := time.NewTicker(blockPollInterval)
+	defer ticker.Stop()
+	for {
+		blockNum, err := client.BlockNumber(ctx)
+		if err == nil && blockNum >= target {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"log/slog"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+// headFeedMockEthClient delivers heads to header subscriptions from a fake feed,
+// then ends the subscription with feedErr, or keeps it open if feedErr is nil.
+// The block number is head, or polledHead after the first call if set.
+type headFeedMockEthClient struct {
+	*MockEthClient
+	head         uint64
+	polledHead   uint64
+	heads        []uint64
+	feedErr      error
+	subscribeErr error
+	mu           sync.Mutex
+	calls        int
+}
+
+func (m *headFeedMockEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if m.calls > 1 && m.polledHead != 0 {
+		return m.polledHead, nil
+	}
+	return m.head, nil
+}
+
+func (m *headFeedMockEthClient) SubscribeNewHead(
+	ctx context.Context, ch chan<- *types.Header) (ethereum.Subscription, error) {
+	if m.subscribeErr != nil {
+		return nil, m.subscribeErr
+	}
+	return event.NewSubscription(func(unsub <-chan struct{}) error {
+		for _, number := range m.heads {
+			select {
+			case ch <- &types.Header{Number: new(big.Int).SetUint64(number)}:
+			case <-unsub:
+				return nil
+			}
+		}
+		if m.feedErr != nil {
+			return m.feedErr
+		}
+		<-unsub
+		return nil
+	}), nil
+}
+
+func TestWaitForBlock(t *testing.T) {
+	testCases := []struct {
+		name        string
+		client      tx.EthClient
+		target      uint64
+		subscribe   bool
+		expectedErr error
+	}{
+		{
+			name:      "target reached by subscribed heads",
+			client:    &headFeedMockEthClient{head: 100, heads: []uint64{101, 102, 103}},
+			target:    103,
+			subscribe: true,
+		},
+		{
+			name:        "target not reached by subscribed heads",
+			client:      &headFeedMockEthClient{head: 100, heads: []uint64{101, 102}},
+			target:      103,
+			subscribe:   true,
+			expectedErr: context.DeadlineExceeded,
+		},
+		{
+			name:      "target already reached when subscribing",
+			client:    &headFeedMockEthClient{head: 103},
+			target:    103,
+			subscribe: true,
+		},
+		{
+			name:      "polling on http endpoint",
+			client:    &headFeedMockEthClient{head: 103, subscribeErr: rpc.ErrNotificationsUnsupported},
+			target:    103,
+			subscribe: true,
+		},
+		{
+			name: "polling after subscription fails",
+			client: &headFeedMockEthClient{
+				head: 100, polledHead: 103, heads: []uint64{101}, feedErr: errors.New("connection lost"),
+			},
+			target:    103,
+			subscribe: true,
+		},
+		{
+			name:      "polling on client without subscriptions",
+			client:    &confirmMockEthClient{head: 103},
+			target:    103,
+			subscribe: true,
+		},
+		{
+			name:        "polling when not subscribing",
+			client:      &headFeedMockEthClient{head: 100, heads: []uint64{103}},
+			target:      103,
+			expectedErr: context.DeadlineExceeded,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			err := tx.WaitForBlock(ctx, tc.client, tc.target, tc.subscribe, slog.Default())
+			if tc.expectedErr != nil {
+				assert.Assert(t, errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NilError(t, err)
+		})
+	}
+}
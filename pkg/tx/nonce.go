@@ -0,0 +1,148 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// NonceManager hands out nonces for the transactions of a single account. It
+// tracks the account's mined nonce and pending nonce on chain alongside the next
+// nonce reserved locally, so that several transactions sent in one run get
+// sequential nonces without re-querying the node in between.
+//
+// Nonces that were reserved but never broadcast leave a gap that would block
+// every later transaction. With fillGaps set such nonces are handed out again
+// before new ones; otherwise they are reported as an error.
+type NonceManager struct {
+	client   EthClient
+	address  common.Address
+	fillGaps bool
+
+	mu      sync.Mutex
+	latest  uint64   // nonce of the next tx to be mined
+	pending uint64   // nonce after the last tx in the node's pending pool
+	next    uint64   // next nonce to reserve locally
+	gaps    []uint64 // released nonces to reuse, in ascending order
+}
+
+func NewNonceManager(
+	ctx context.Context,
+	client EthClient,
+	address common.Address,
+	fillGaps bool,
+) (*NonceManager, error) {
+	m := &NonceManager{
+		client:   client,
+		address:  address,
+		fillGaps: fillGaps,
+	}
+	if err := m.Sync(ctx); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// Sync refreshes the chain nonces of the account. Locally reserved nonces the
+// node no longer knows of, e.g. because their txs were dropped, become gaps.
+func (m *NonceManager) Sync(ctx context.Context) error {
+	pending, err := m.client.PendingNonceAt(ctx, m.address)
+	if err != nil {
+		return fmt.Errorf("failed to get pending nonce: %w", err)
+	}
+	latest, err := m.client.NonceAt(ctx, m.address, nil)
+	if err != nil {
+		return fmt.Errorf("failed to get latest nonce: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.latest, m.pending = latest, pending
+	switch {
+	case m.next <= pending:
+		m.next = pending
+		m.gaps = nil
+	case m.fillGaps:
+		m.gaps = m.gaps[:0]
+		for n := pending; n < m.next; n++ {
+			m.gaps = append(m.gaps, n)
+		}
+	default:
+		return fmt.Errorf("nonce gap: node pending nonce is %d but nonces up to %d were used", pending, m.next-1)
+	}
+	return nil
+}
+
+// HasPending reports whether the account has txs in the pending pool that are not yet mined.
+func (m *NonceManager) HasPending() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.pending > m.latest
+}
+
+// PendingNonces returns the nonces of the account's pending txs, as of the last Sync.
+func (m *NonceManager) PendingNonces() []uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var nonces []uint64
+	for n := m.latest; n < m.pending; n++ {
+		nonces = append(nonces, n)
+	}
+	return nonces
+}
+
+// Next reserves the next nonce, reusing the lowest gap first.
+func (m *NonceManager) Next() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.gaps) > 0 {
+		n := m.gaps[0]
+		m.gaps = m.gaps[1:]
+		return n
+	}
+	n := m.next
+	m.next++
+	return n
+}
+
+// Release returns a reserved nonce whose tx was never broadcast.
+func (m *NonceManager) Release(nonce uint64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if nonce >= m.next || slices.Contains(m.gaps, nonce) {
+		return fmt.Errorf("nonce %d was not reserved", nonce)
+	}
+	if nonce == m.next-1 {
+		m.next--
+		for len(m.gaps) > 0 && m.gaps[len(m.gaps)-1] == m.next-1 {
+			m.gaps = m.gaps[:len(m.gaps)-1]
+			m.next--
+		}
+		return nil
+	}
+	if !m.fillGaps {
+		return fmt.Errorf("releasing nonce %d leaves a gap before nonce %d", nonce, m.next-1)
+	}
+	i, _ := slices.BinarySearch(m.gaps, nonce)
+	m.gaps = slices.Insert(m.gaps, i, nonce)
+	return nil
+}
+
+// Replace returns nonce for a tx that deliberately replaces the pending tx at that
+// nonce. The nonce must belong to a tx that is not yet mined.
+func (m *NonceManager) Replace(nonce uint64) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if nonce < m.latest {
+		return 0, fmt.Errorf("nonce %d already mined, account nonce is %d", nonce, m.latest)
+	}
+	if nonce >= max(m.pending, m.next) {
+		return 0, fmt.Errorf("no pending tx to replace at nonce %d, pending nonce is %d",
+			nonce, max(m.pending, m.next))
+	}
+	return nonce, nil
+}
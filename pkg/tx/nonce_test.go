@@ -0,0 +1,108 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gotest.tools/assert"
+)
+
+type nonceMockEthClient struct {
+	*MockEthClient
+	latest  uint64
+	pending uint64
+}
+
+func (m *nonceMockEthClient) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return m.pending, nil
+}
+
+func (m *nonceMockEthClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return m.latest, nil
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	address := common.HexToAddress("0x01")
+
+	t.Run("sequential nonces from pending nonce", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 3, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, false)
+		assert.NilError(t, err)
+		assert.Assert(t, m.HasPending())
+		assert.DeepEqual(t, m.PendingNonces(), []uint64{3, 4})
+		assert.Equal(t, m.Next(), uint64(5))
+		assert.Equal(t, m.Next(), uint64(6))
+	})
+
+	t.Run("release last nonce", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 5, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, false)
+		assert.NilError(t, err)
+		assert.Assert(t, !m.HasPending())
+		n := m.Next()
+		assert.NilError(t, m.Release(n))
+		assert.Equal(t, m.Next(), n)
+	})
+
+	t.Run("error, release leaves gap", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 5, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, false)
+		assert.NilError(t, err)
+		n := m.Next()
+		m.Next()
+		assert.ErrorContains(t, m.Release(n), "leaves a gap")
+	})
+
+	t.Run("fill released gap", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 5, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, true)
+		assert.NilError(t, err)
+		n := m.Next()
+		m.Next()
+		assert.NilError(t, m.Release(n))
+		assert.Equal(t, m.Next(), n)
+		assert.Equal(t, m.Next(), uint64(7))
+	})
+
+	t.Run("fill dropped txs on sync", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 5, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, true)
+		assert.NilError(t, err)
+		m.Next()
+		m.Next()
+		m.Next()
+		client.pending = 6
+		assert.NilError(t, m.Sync(ctx))
+		assert.Equal(t, m.Next(), uint64(6))
+		assert.Equal(t, m.Next(), uint64(7))
+		assert.Equal(t, m.Next(), uint64(8))
+	})
+
+	t.Run("error, dropped txs without fill gaps", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 5, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, false)
+		assert.NilError(t, err)
+		m.Next()
+		m.Next()
+		client.pending = 6
+		assert.ErrorContains(t, m.Sync(ctx), "nonce gap")
+	})
+
+	t.Run("replace pending nonce", func(t *testing.T) {
+		client := &nonceMockEthClient{latest: 3, pending: 5}
+		m, err := tx.NewNonceManager(ctx, client, address, false)
+		assert.NilError(t, err)
+		n, err := m.Replace(4)
+		assert.NilError(t, err)
+		assert.Equal(t, n, uint64(4))
+		_, err = m.Replace(2)
+		assert.ErrorContains(t, err, "already mined")
+		_, err = m.Replace(5)
+		assert.ErrorContains(t, err, "no pending tx to replace")
+		assert.Equal(t, m.Next(), uint64(5))
+	})
+}
@@ -0,0 +1,179 @@
+package tx
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// RelayMethod is the JSON-RPC method a Relay submits txs with.
+type RelayMethod string
+
+const (
+	// RelayMethodRawTransaction sends the tx as is, to private RPC endpoints such
+	// as Flashbots Protect or MEV Blocker that keep it out of the public mempool.
+	RelayMethodRawTransaction RelayMethod = "eth_sendRawTransaction"
+	// RelayMethodPrivateTransaction sends the tx with the last block it may be
+	// included in. Relays such as Flashbots require requests to be signed with
+	// RelaySigningTransport.
+	RelayMethodPrivateTransaction RelayMethod = "eth_sendPrivateTransaction"
+	// RelayMethodBundle sends the tx as a single tx bundle for every target block.
+	// Relays such as Flashbots require requests to be signed with RelaySigningTransport.
+	RelayMethodBundle RelayMethod = "eth_sendBundle"
+)
+
+// RelaySignatureHeader is the header RelaySigningTransport signs requests in.
+const RelaySignatureHeader = "X-Flashbots-Signature"
+
+// RelayMethods lists the supported relay methods.
+var RelayMethods = []RelayMethod{
+	RelayMethodRawTransaction,
+	RelayMethodPrivateTransaction,
+	RelayMethodBundle,
+}
+
+type privateTxArgs struct {
+	Tx             hexutil.Bytes  `json:"tx"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}
+
+type bundleArgs struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
+
+// Relay submits signed txs to a private relay instead of the public mempool.
+type Relay struct {
+	client *rpc.Client
+	method RelayMethod
+}
+
+// NewRelay returns a relay that submits txs through client with method.
+func NewRelay(client *rpc.Client, method RelayMethod) (*Relay, error) {
+	switch method {
+	case RelayMethodRawTransaction, RelayMethodPrivateTransaction, RelayMethodBundle:
+	default:
+		return nil, fmt.Errorf("unknown relay method: %q", method)
+	}
+	return &Relay{client: client, method: method}, nil
+}
+
+// DialRelay connects to the relay at url with the given client options and
+// returns a relay submitting txs with method.
+func DialRelay(ctx context.Context, url string, method RelayMethod, opts ...rpc.ClientOption) (*Relay, error) {
+	client, err := rpc.DialOptions(ctx, url, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to relay: %w", err)
+	}
+	relay, err := NewRelay(client, method)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return relay, nil
+}
+
+// Send submits signedTx for inclusion in one of the blocks from firstBlock to
+// lastBlock. Bundles are sent once for each of them, while a private tx is sent
+// once with lastBlock as its deadline. Raw txs have no deadline and may still
+// be included later. Errors are classified with ClassifyError.
+func (r *Relay) Send(ctx context.Context, signedTx *types.Transaction, firstBlock, lastBlock uint64) error {
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return fmt.Errorf("failed to encode tx: %w", err)
+	}
+
+	switch r.method {
+	case RelayMethodRawTransaction:
+		var hash common.Hash
+		err = r.client.CallContext(ctx, &hash, string(r.method), hexutil.Bytes(raw))
+	case RelayMethodPrivateTransaction:
+		var result json.RawMessage
+		err = r.client.CallContext(ctx, &result, string(r.method), privateTxArgs{
+			Tx:             raw,
+			MaxBlockNumber: hexutil.Uint64(lastBlock),
+		})
+	case RelayMethodBundle:
+		err = r.sendBundles(ctx, raw, firstBlock, lastBlock)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to send tx to relay with %s: %w", r.method, ClassifyError(err))
+	}
+	return nil
+}
+
+// sendBundles sends the bundle of raw for every block from firstBlock to
+// lastBlock at once, so that sending them doesn't outlast the first block. The
+// error for the earliest block is returned.
+func (r *Relay) sendBundles(ctx context.Context, raw []byte, firstBlock, lastBlock uint64) error {
+	if lastBlock < firstBlock {
+		return nil
+	}
+	errs := make([]error, lastBlock-firstBlock+1)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var result json.RawMessage
+			errs[i] = r.client.CallContext(ctx, &result, string(r.method), bundleArgs{
+				Txs:         []hexutil.Bytes{raw},
+				BlockNumber: hexutil.Uint64(firstBlock + uint64(i)),
+			})
+		}()
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RelaySigningTransport returns a transport that sends requests with base,
+// signing the body of each with key in RelaySignatureHeader, as relays such as
+// Flashbots require for eth_sendBundle and eth_sendPrivateTransaction. The key
+// only identifies the sender to the relay and needs no funds.
+func RelaySigningTransport(key *ecdsa.PrivateKey, base http.RoundTripper) http.RoundTripper {
+	return &relaySigningTransport{key: key, address: crypto.PubkeyToAddress(key.PublicKey), base: base}
+}
+
+type relaySigningTransport struct {
+	key     *ecdsa.PrivateKey
+	address common.Address
+	base    http.RoundTripper
+}
+
+// RoundTrip signs the EIP-191 hash of the hex encoded keccak256 hash of the body.
+func (t *relaySigningTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read relay request: %w", err)
+		}
+	}
+	sig, err := crypto.Sign(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), t.key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign relay request: %w", err)
+	}
+	signed := req.Clone(req.Context())
+	signed.Body = io.NopCloser(bytes.NewReader(body))
+	signed.Header.Set(RelaySignatureHeader, t.address.Hex()+":"+hexutil.Encode(sig))
+	return t.base.RoundTrip(signed)
+}
@@ -0,0 +1,210 @@
+package tx_test
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"gotest.tools/assert"
+)
+
+type relayCall struct {
+	Method string
+	Tx     hexutil.Bytes
+	Block  uint64
+}
+
+// relayStandIn serves the relay methods over JSON-RPC, recording every call and
+// failing them with err if set.
+type relayStandIn struct {
+	mu    sync.Mutex
+	calls []relayCall
+	err   error
+}
+
+func (r *relayStandIn) record(call relayCall) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call)
+	return r.err
+}
+
+func (r *relayStandIn) SendRawTransaction(raw hexutil.Bytes) (common.Hash, error) {
+	return crypto.Keccak256Hash(raw), r.record(relayCall{Method: "eth_sendRawTransaction", Tx: raw})
+}
+
+func (r *relayStandIn) SendPrivateTransaction(args struct {
+	Tx             hexutil.Bytes  `json:"tx"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}) (common.Hash, error) {
+	return crypto.Keccak256Hash(args.Tx), r.record(relayCall{
+		Method: "eth_sendPrivateTransaction", Tx: args.Tx, Block: uint64(args.MaxBlockNumber),
+	})
+}
+
+func (r *relayStandIn) SendBundle(args struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}) (map[string]common.Hash, error) {
+	return map[string]common.Hash{"bundleHash": crypto.Keccak256Hash(args.Txs[0])}, r.record(relayCall{
+		Method: "eth_sendBundle", Tx: args.Txs[0], Block: uint64(args.BlockNumber),
+	})
+}
+
+func TestRelay(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	to := common.HexToAddress("0x01")
+	signedTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     5,
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &to,
+	})
+	assert.NilError(t, err)
+	raw, err := signedTx.MarshalBinary()
+	assert.NilError(t, err)
+
+	testCases := []struct {
+		name              string
+		method            tx.RelayMethod
+		relayErr          error
+		expectedCalls     []relayCall
+		expectedErr       error
+		errExpectedOutput string
+	}{
+		{
+			name:          "raw transaction",
+			method:        tx.RelayMethodRawTransaction,
+			expectedCalls: []relayCall{{Method: "eth_sendRawTransaction", Tx: raw}},
+		},
+		{
+			name:          "private transaction",
+			method:        tx.RelayMethodPrivateTransaction,
+			expectedCalls: []relayCall{{Method: "eth_sendPrivateTransaction", Tx: raw, Block: 103}},
+		},
+		{
+			name:   "bundle for every target block",
+			method: tx.RelayMethodBundle,
+			expectedCalls: []relayCall{
+				{Method: "eth_sendBundle", Tx: raw, Block: 101},
+				{Method: "eth_sendBundle", Tx: raw, Block: 102},
+				{Method: "eth_sendBundle", Tx: raw, Block: 103},
+			},
+		},
+		{
+			name:     "error classified",
+			method:   tx.RelayMethodBundle,
+			relayErr: errors.New("nonce too low"),
+			expectedCalls: []relayCall{
+				{Method: "eth_sendBundle", Tx: raw, Block: 101},
+				{Method: "eth_sendBundle", Tx: raw, Block: 102},
+				{Method: "eth_sendBundle", Tx: raw, Block: 103},
+			},
+			expectedErr: tx.ErrNonceTooLow,
+		},
+		{
+			name:              "error, unknown method",
+			method:            "eth_sendMegaBundle",
+			errExpectedOutput: `unknown relay method: "eth_sendMegaBundle"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			standIn := &relayStandIn{err: tc.relayErr}
+			server := rpc.NewServer()
+			defer server.Stop()
+			assert.NilError(t, server.RegisterName("eth", standIn))
+			httpServer := httptest.NewServer(server)
+			defer httpServer.Close()
+
+			relay, err := tx.DialRelay(context.Background(), httpServer.URL, tc.method)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+
+			err = relay.Send(context.Background(), signedTx, 101, 103)
+			if tc.expectedErr != nil {
+				assert.Assert(t, errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+			} else {
+				assert.NilError(t, err)
+			}
+			// Bundles for the target blocks are sent concurrently.
+			slices.SortFunc(standIn.calls, func(a, b relayCall) int { return cmp.Compare(a.Block, b.Block) })
+			assert.DeepEqual(t, tc.expectedCalls, standIn.calls)
+		})
+	}
+}
+
+func TestRelaySigningTransport(t *testing.T) {
+	relayKey, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+
+	standIn := &relayStandIn{}
+	server := rpc.NewServer()
+	defer server.Stop()
+	assert.NilError(t, server.RegisterName("eth", standIn))
+	var mu sync.Mutex
+	var signers []common.Address
+	var apiKeys []string
+	httpServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NilError(t, err)
+		address, sig, ok := strings.Cut(r.Header.Get(tx.RelaySignatureHeader), ":")
+		assert.Assert(t, ok, "missing signature header")
+		sigBytes, err := hexutil.Decode(sig)
+		assert.NilError(t, err)
+		pubkey, err := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), sigBytes)
+		assert.NilError(t, err)
+		assert.Equal(t, address, crypto.PubkeyToAddress(*pubkey).Hex())
+
+		mu.Lock()
+		signers = append(signers, crypto.PubkeyToAddress(*pubkey))
+		apiKeys = append(apiKeys, r.Header.Get("X-Api-Key"))
+		mu.Unlock()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		server.ServeHTTP(w, r)
+	}))
+	defer httpServer.Close()
+
+	client := &http.Client{Transport: tx.RelaySigningTransport(relayKey, http.DefaultTransport)}
+	relay, err := tx.DialRelay(context.Background(), httpServer.URL, tx.RelayMethodBundle,
+		rpc.WithHTTPClient(client), rpc.WithHeader("X-Api-Key", "secret"))
+	assert.NilError(t, err)
+
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	signedTx, err := types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		GasTipCap: big.NewInt(1),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, relay.Send(context.Background(), signedTx, 101, 102))
+
+	relayAddress := crypto.PubkeyToAddress(relayKey.PublicKey)
+	assert.DeepEqual(t, []common.Address{relayAddress, relayAddress}, signers)
+	assert.DeepEqual(t, []string{"secret", "secret"}, apiKeys)
+	assert.Equal(t, 2, len(standIn.calls))
+}
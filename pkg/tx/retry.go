@@ -0,0 +1,203 @@
+package tx
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"time"
+)
+
+// Backoff is how the wait for inclusion grows from one attempt to the next.
+type Backoff string
+
+const (
+	// BackoffConstant waits the same for every attempt.
+	BackoffConstant Backoff = "constant"
+	// BackoffLinear waits n times as long for the n-th attempt.
+	BackoffLinear Backoff = "linear"
+	// BackoffExponential doubles the wait with every attempt.
+	BackoffExponential Backoff = "exponential"
+)
+
+// minBoostPercent is the smallest fee bump nodes accept for a replacement tx.
+const minBoostPercent = 10
+
+// blockPollInterval is how often the block number is polled when waiting in blocks.
+const blockPollInterval = 2 * time.Second
+
+// RetryPolicy configures how WaitMinedWithRetry resubmits a tx that is not
+// included in time.
+type RetryPolicy struct {
+	// Attempts is the number of times the tx is submitted, including the first.
+	Attempts int
+	// Wait is how long the first attempt waits for inclusion. Ignored if
+	// WaitBlocks is set.
+	Wait time.Duration
+	// WaitBlocks is how many blocks the first attempt waits for inclusion.
+	WaitBlocks uint64
+	// SubscribeHeads counts WaitBlocks through a header subscription instead of
+	// polling the block number, if the client supports one.
+	SubscribeHeads bool
+	// BoostPercent is how much fees are raised for every resubmission.
+	BoostPercent uint64
+	// Backoff is how the wait grows with every attempt.
+	Backoff Backoff
+	// MaxWait caps how long any attempt waits for inclusion. 0 for no cap.
+	MaxWait time.Duration
+	// MaxWaitBlocks caps how many blocks any attempt waits for inclusion. 0 for
+	// no cap.
+	MaxWaitBlocks uint64
+	// Strategy prices the tx and its replacements. Defaults to NodeGasStrategy.
+	Strategy GasStrategy
+	// Confirmations is how many blocks deep the tx must be, counting its own
+	// block, before it is reported as mined. 0 or 1 returns on inclusion.
+	Confirmations uint64
+	// Ceiling bounds the fees of replacements, if set.
+	Ceiling *FeeCeiling
+}
+
+// DefaultRetryPolicy submits a tx up to 10 times, waiting 60 seconds for each
+// attempt and boosting fees by 10%.
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		Attempts:     10,
+		Wait:         60 * time.Second,
+		BoostPercent: minBoostPercent,
+		Backoff:      BackoffConstant,
+		Strategy:     NodeGasStrategy{},
+	}
+}
+
+// Validate checks that the policy can be used to retry a tx.
+func (p RetryPolicy) Validate() error {
+	if p.Attempts < 1 {
+		return fmt.Errorf("retry attempts must be at least 1: %d", p.Attempts)
+	}
+	if p.WaitBlocks == 0 && p.Wait <= 0 {
+		return fmt.Errorf("retry wait must be positive")
+	}
+	if p.MaxWait < 0 {
+		return fmt.Errorf("retry max wait must not be negative: %s", p.MaxWait)
+	}
+	if p.BoostPercent < minBoostPercent {
+		return fmt.Errorf("boost percent must be at least %d for nodes to accept replacements: %d",
+			minBoostPercent, p.BoostPercent)
+	}
+	switch p.Backoff {
+	case BackoffConstant, BackoffLinear, BackoffExponential, "":
+	default:
+		return fmt.Errorf("unknown backoff: %q", p.Backoff)
+	}
+	return nil
+}
+
+// backoffFactor returns how many times the first attempt's wait the given
+// zero-based attempt waits.
+func (p RetryPolicy) backoffFactor(attempt int) uint64 {
+	switch p.Backoff {
+	case BackoffLinear:
+		return uint64(attempt) + 1
+	case BackoffExponential:
+		return 1 << min(attempt, 63)
+	default:
+		return 1
+	}
+}
+
+// WaitDuration returns how long the given zero-based attempt waits for inclusion,
+// when waiting is measured in time. The wait is capped at MaxWait, if set.
+func (p RetryPolicy) WaitDuration(attempt int) time.Duration {
+	maxWait := time.Duration(math.MaxInt64)
+	if p.MaxWait > 0 {
+		maxWait = p.MaxWait
+	}
+	if p.Wait <= 0 {
+		return p.Wait
+	}
+	// Checked before multiplying, so that long backoffs can't overflow.
+	factor := p.backoffFactor(attempt)
+	if factor > uint64(maxWait/p.Wait) {
+		return maxWait
+	}
+	return p.Wait * time.Duration(factor)
+}
+
+// WaitBlocksFor returns how many blocks the given zero-based attempt waits for
+// inclusion, when waiting is measured in blocks. The wait is capped at
+// MaxWaitBlocks, if set.
+func (p RetryPolicy) WaitBlocksFor(attempt int) uint64 {
+	maxBlocks := uint64(math.MaxUint64)
+	if p.MaxWaitBlocks > 0 {
+		maxBlocks = p.MaxWaitBlocks
+	}
+	if p.WaitBlocks == 0 {
+		return 0
+	}
+	factor := p.backoffFactor(attempt)
+	if factor > maxBlocks/p.WaitBlocks {
+		return maxBlocks
+	}
+	return p.WaitBlocks * factor
+}
+
+// WorstCaseCost returns the most a tx with the given gas limit, first priced at
+// gasTip and gasFeeCap, can cost in wei over all attempts of the policy. Each
+// boost is assumed to raise fees by BoostPercent, as boosts above that follow a
+// rise in the strategy's suggestion that cannot be foreseen. The fee ceiling's
+// caps and remaining budget bound the result.
+func (p RetryPolicy) WorstCaseCost(gasLimit uint64, gasTip, gasFeeCap *big.Int) *big.Int {
+	maxFeeCap := gasFeeCap
+	for i := 1; i < p.Attempts; i++ {
+		gasTip, gasFeeCap = boostFees(gasTip, gasFeeCap, gasTip, gasFeeCap, p.BoostPercent)
+		if p.Ceiling != nil && p.Ceiling.MaxGasFeeCap != nil && gasFeeCap.Cmp(p.Ceiling.MaxGasFeeCap) > 0 {
+			maxFeeCap = p.Ceiling.MaxGasFeeCap
+			break
+		}
+		maxFeeCap = gasFeeCap
+	}
+	cost := new(big.Int).Mul(maxFeeCap, new(big.Int).SetUint64(gasLimit))
+	if p.Ceiling != nil && p.Ceiling.MaxTotalSpend != nil {
+		remaining := new(big.Int).Sub(p.Ceiling.MaxTotalSpend, p.Ceiling.Spent())
+		if remaining.Cmp(cost) < 0 {
+			cost = remaining
+		}
+	}
+	return cost
+}
+
+func (p RetryPolicy) strategy() GasStrategy {
+	if p.Strategy == nil {
+		return NodeGasStrategy{}
+	}
+	return p.Strategy
+}
+
+// attemptContext returns a context that is done once the given attempt has
+// waited long enough for inclusion, and a description of that wait.
+func (p RetryPolicy) attemptContext(
+	ctx context.Context,
+	client EthClient,
+	attempt int,
+	logger *slog.Logger,
+) (context.Context, context.CancelFunc, string, error) {
+	if p.WaitBlocks == 0 {
+		wait := p.WaitDuration(attempt)
+		attemptCtx, cancel := context.WithTimeout(ctx, wait)
+		return attemptCtx, cancel, wait.String(), nil
+	}
+
+	start, err := client.BlockNumber(ctx)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("failed to get block number: %w", err)
+	}
+	blocks := p.WaitBlocksFor(attempt)
+	attemptCtx, cancel := context.WithCancel(ctx)
+	go func() {
+		if WaitForBlock(attemptCtx, client, start+blocks, p.SubscribeHeads, logger) == nil {
+			cancel()
+		}
+	}()
+	return attemptCtx, cancel, fmt.Sprintf("%d blocks", blocks), nil
+}
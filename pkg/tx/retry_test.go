@@ -0,0 +1,251 @@
+package tx_test
+
+import (
+	"eigen-operator-cli/pkg/tx"
+	"math"
+	"math/big"
+	"testing"
+	"time"
+
+	"gotest.tools/assert"
+)
+
+func TestRetryPolicy(t *testing.T) {
+	testCases := []struct {
+		name              string
+		policy            tx.RetryPolicy
+		expectedWaits     []time.Duration
+		expectedBlocks    []uint64
+		errExpectedOutput string
+	}{
+		{
+			name:          "default",
+			policy:        tx.DefaultRetryPolicy(),
+			expectedWaits: []time.Duration{60 * time.Second, 60 * time.Second, 60 * time.Second},
+		},
+		{
+			name: "linear seconds",
+			policy: tx.RetryPolicy{
+				Attempts:     5,
+				Wait:         12 * time.Second,
+				BoostPercent: 15,
+				Backoff:      tx.BackoffLinear,
+			},
+			expectedWaits: []time.Duration{12 * time.Second, 24 * time.Second, 36 * time.Second},
+		},
+		{
+			name: "exponential seconds",
+			policy: tx.RetryPolicy{
+				Attempts:     5,
+				Wait:         10 * time.Second,
+				BoostPercent: 10,
+				Backoff:      tx.BackoffExponential,
+			},
+			expectedWaits: []time.Duration{10 * time.Second, 20 * time.Second, 40 * time.Second},
+		},
+		{
+			name: "exponential seconds, capped",
+			policy: tx.RetryPolicy{
+				Attempts:     5,
+				Wait:         10 * time.Second,
+				BoostPercent: 10,
+				Backoff:      tx.BackoffExponential,
+				MaxWait:      30 * time.Second,
+			},
+			expectedWaits: []time.Duration{10 * time.Second, 20 * time.Second, 30 * time.Second, 30 * time.Second},
+		},
+		{
+			name: "constant blocks",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				WaitBlocks:   2,
+				BoostPercent: 10,
+				Backoff:      tx.BackoffConstant,
+			},
+			expectedBlocks: []uint64{2, 2, 2},
+		},
+		{
+			name: "exponential blocks",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				WaitBlocks:   3,
+				BoostPercent: 10,
+				Backoff:      tx.BackoffExponential,
+			},
+			expectedBlocks: []uint64{3, 6, 12},
+		},
+		{
+			name: "linear blocks, capped",
+			policy: tx.RetryPolicy{
+				Attempts:      4,
+				WaitBlocks:    3,
+				BoostPercent:  10,
+				Backoff:       tx.BackoffLinear,
+				MaxWaitBlocks: 7,
+			},
+			expectedBlocks: []uint64{3, 6, 7, 7},
+		},
+		{
+			name: "error, no attempts",
+			policy: tx.RetryPolicy{
+				Wait:         time.Second,
+				BoostPercent: 10,
+			},
+			errExpectedOutput: "retry attempts must be at least 1: 0",
+		},
+		{
+			name: "error, no wait",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				BoostPercent: 10,
+			},
+			errExpectedOutput: "retry wait must be positive",
+		},
+		{
+			name: "error, boost too small",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				Wait:         time.Second,
+				BoostPercent: 5,
+			},
+			errExpectedOutput: "boost percent must be at least 10 for nodes to accept replacements: 5",
+		},
+		{
+			name: "error, negative max wait",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				Wait:         time.Second,
+				BoostPercent: 10,
+				MaxWait:      -time.Second,
+			},
+			errExpectedOutput: "retry max wait must not be negative: -1s",
+		},
+		{
+			name: "error, unknown backoff",
+			policy: tx.RetryPolicy{
+				Attempts:     3,
+				Wait:         time.Second,
+				BoostPercent: 10,
+				Backoff:      "fibonacci",
+			},
+			errExpectedOutput: `unknown backoff: "fibonacci"`,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.Validate()
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			for attempt, expected := range tc.expectedWaits {
+				assert.Equal(t, expected, tc.policy.WaitDuration(attempt))
+			}
+			for attempt, expected := range tc.expectedBlocks {
+				assert.Equal(t, expected, tc.policy.WaitBlocksFor(attempt))
+			}
+		})
+	}
+}
+
+func TestRetryPolicyHighAttempts(t *testing.T) {
+	testCases := []struct {
+		name           string
+		policy         tx.RetryPolicy
+		attempt        int
+		expectedWait   time.Duration
+		expectedBlocks uint64
+	}{
+		{
+			name:         "exponential, capped",
+			policy:       tx.RetryPolicy{Wait: time.Minute, Backoff: tx.BackoffExponential, MaxWait: time.Hour},
+			attempt:      100,
+			expectedWait: time.Hour,
+		},
+		{
+			name:         "exponential, uncapped",
+			policy:       tx.RetryPolicy{Wait: time.Minute, Backoff: tx.BackoffExponential},
+			attempt:      100,
+			expectedWait: time.Duration(math.MaxInt64),
+		},
+		{
+			name:         "linear, uncapped",
+			policy:       tx.RetryPolicy{Wait: time.Hour, Backoff: tx.BackoffLinear},
+			attempt:      math.MaxInt32,
+			expectedWait: time.Duration(math.MaxInt64),
+		},
+		{
+			name:         "cap below first wait",
+			policy:       tx.RetryPolicy{Wait: time.Minute, Backoff: tx.BackoffExponential, MaxWait: time.Second},
+			attempt:      0,
+			expectedWait: time.Second,
+		},
+		{
+			name:           "exponential blocks, capped",
+			policy:         tx.RetryPolicy{WaitBlocks: 3, Backoff: tx.BackoffExponential, MaxWaitBlocks: 100},
+			attempt:        64,
+			expectedBlocks: 100,
+		},
+		{
+			name:           "exponential blocks, uncapped",
+			policy:         tx.RetryPolicy{WaitBlocks: 3, Backoff: tx.BackoffExponential},
+			attempt:        64,
+			expectedBlocks: math.MaxUint64,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.policy.Wait > 0 {
+				assert.Equal(t, tc.expectedWait, tc.policy.WaitDuration(tc.attempt))
+			}
+			if tc.policy.WaitBlocks > 0 {
+				assert.Equal(t, tc.expectedBlocks, tc.policy.WaitBlocksFor(tc.attempt))
+			}
+		})
+	}
+}
+
+func TestRetryPolicyWorstCaseCost(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	testCases := []struct {
+		name         string
+		policy       tx.RetryPolicy
+		expectedCost *big.Int
+	}{
+		{
+			name:         "single attempt",
+			policy:       tx.RetryPolicy{Attempts: 1, BoostPercent: 10},
+			expectedCost: new(big.Int).Mul(gwei(11), big.NewInt(100_000)),
+		},
+		{
+			name:         "two boosts",
+			policy:       tx.RetryPolicy{Attempts: 3, BoostPercent: 10},
+			expectedCost: new(big.Int).Mul(big.NewInt(13_310_000_002), big.NewInt(100_000)),
+		},
+		{
+			name: "capped fee cap",
+			policy: tx.RetryPolicy{
+				Attempts:     10,
+				BoostPercent: 10,
+				Ceiling:      &tx.FeeCeiling{MaxGasFeeCap: gwei(12)},
+			},
+			expectedCost: new(big.Int).Mul(gwei(12), big.NewInt(100_000)),
+		},
+		{
+			name: "bounded by budget",
+			policy: tx.RetryPolicy{
+				Attempts:     10,
+				BoostPercent: 10,
+				Ceiling:      &tx.FeeCeiling{MaxTotalSpend: gwei(1_000_000)},
+			},
+			expectedCost: gwei(1_000_000),
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cost := tc.policy.WorstCaseCost(100_000, gwei(1), gwei(11))
+			assert.Equal(t, tc.expectedCost.String(), cost.String())
+		})
+	}
+}
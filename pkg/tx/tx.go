@@ -2,12 +2,14 @@ package tx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"math/big"
-	"strings"
-	"time"
+	"slices"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
@@ -20,6 +22,9 @@ type EthClient interface {
 	NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	BlockNumber(ctx context.Context) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
 }
 
 // Primary target for EthClient is go-ethereum/ethclient/Client
@@ -55,9 +60,51 @@ func SuggestGasTipCapAndPrice(ctx context.Context, client EthClient) (
 	return gasTip, gasPrice, nil
 }
 
-// Boosts the gas tip and base fee by just above 10% of highest recent suggestion from client.
-func BoostTipForTransactOpts(ctx context.Context, opts *bind.TransactOpts, client EthClient, logger *slog.Logger) error {
+// gasLimitMarginBasis is the number of basis points in a gas limit multiplier of 1.
+const gasLimitMarginBasis = 10000
 
+// GasLimitWithMargin multiplies an estimated gas limit by a safety margin, capping
+// the result at maxGasLimit when it is non-zero. An estimate that already exceeds
+// the cap is an error, since the transaction would run out of gas.
+func GasLimitWithMargin(estimate uint64, multiplier float64, maxGasLimit uint64) (uint64, error) {
+	if multiplier < 1 {
+		return 0, fmt.Errorf("gas limit multiplier must be at least 1: %v", multiplier)
+	}
+	if maxGasLimit != 0 && estimate > maxGasLimit {
+		return 0, fmt.Errorf("estimated gas %d exceeds max gas limit %d", estimate, maxGasLimit)
+	}
+	// The multiplier is applied in basis points, as float products such as
+	// 21000 * 1.1 = 23100.000000000004 would round up a gas too many.
+	bps := new(big.Int).SetUint64(uint64(math.Round(multiplier * gasLimitMarginBasis)))
+	product := new(big.Int).Mul(new(big.Int).SetUint64(estimate), bps)
+	product.Add(product, big.NewInt(gasLimitMarginBasis-1))
+	product.Div(product, big.NewInt(gasLimitMarginBasis))
+	if !product.IsUint64() {
+		return 0, fmt.Errorf("gas limit overflows: %d * %v", estimate, multiplier)
+	}
+	gasLimit := product.Uint64()
+	if maxGasLimit != 0 && gasLimit > maxGasLimit {
+		gasLimit = maxGasLimit
+	}
+	return gasLimit, nil
+}
+
+// BoostTipForTransactOpts raises the gas params of opts for resubmitting a tx, as
+// decided by strategy. All strategies boost by just above percent of the previous
+// params or their latest suggestion, whichever is higher. The gas price of legacy
+// txs, those with opts.GasPrice set, is boosted the same way.
+func BoostTipForTransactOpts(
+	ctx context.Context,
+	opts *bind.TransactOpts,
+	client EthClient,
+	strategy GasStrategy,
+	percent uint64,
+	logger *slog.Logger,
+) error {
+
+	if opts.GasPrice != nil {
+		return boostGasPriceForTransactOpts(ctx, opts, client, strategy, percent, logger)
+	}
 	if opts.GasTipCap == nil || opts.GasFeeCap == nil {
 		return fmt.Errorf("gas tip cap and gas fee cap must be set")
 	}
@@ -69,56 +116,79 @@ func BoostTipForTransactOpts(ctx context.Context, opts *bind.TransactOpts, clien
 		"base_fee", new(big.Int).Sub(opts.GasFeeCap, opts.GasTipCap).String(),
 	)
 
-	newGasTip, newFeeCap, err := SuggestGasTipCapAndPrice(ctx, client)
-	if err != nil {
-		return fmt.Errorf("failed to suggest gas tip cap and price: %w", err)
-	}
-
-	newBaseFee := new(big.Int).Sub(newFeeCap, newGasTip)
-	if newBaseFee.Cmp(big.NewInt(0)) == -1 {
-		return fmt.Errorf("new base fee cannot be negative: %s", newBaseFee.String())
-	}
 	prevBaseFee := new(big.Int).Sub(opts.GasFeeCap, opts.GasTipCap)
 	if prevBaseFee.Cmp(big.NewInt(0)) == -1 {
 		return fmt.Errorf("base fee cannot be negative: %s", prevBaseFee.String())
 	}
 
-	var maxBaseFee *big.Int
-	if newBaseFee.Cmp(prevBaseFee) == 1 {
-		maxBaseFee = newBaseFee
-	} else {
-		maxBaseFee = prevBaseFee
-	}
-
-	var maxGasTip *big.Int
-	if newGasTip.Cmp(opts.GasTipCap) == 1 {
-		maxGasTip = newGasTip
-	} else {
-		maxGasTip = opts.GasTipCap
+	boostedTip, boostedFeeCap, err := strategy.BoostFees(ctx, client, opts.GasTipCap, opts.GasFeeCap, percent)
+	if err != nil {
+		return err
 	}
 
-	boostedTip := addTenPercentTo(maxGasTip)
-	boostedTip = boostedTip.Add(boostedTip, big.NewInt(1))
-
-	boostedBaseFee := addTenPercentTo(maxBaseFee)
-
 	opts.GasTipCap = boostedTip
-	opts.GasFeeCap = new(big.Int).Add(boostedBaseFee, boostedTip)
+	opts.GasFeeCap = boostedFeeCap
 
 	logger.Info(
-		"boosting gas tip and base fee by 10 percent for faster tx inclusion",
+		"boosting gas tip and base fee for faster tx inclusion",
+		"percent", percent,
 		"boosted_gas_tip_cap", opts.GasTipCap.String(),
 		"boosted_gas_fee_cap", opts.GasFeeCap.String(),
-		"boosted_base_fee", boostedBaseFee.String(),
+		"boosted_base_fee", new(big.Int).Sub(boostedFeeCap, boostedTip).String(),
 	)
 
 	return nil
 }
 
-func addTenPercentTo(value *big.Int) *big.Int {
-	return new(big.Int).Add(value, new(big.Int).Div(value, big.NewInt(10)))
+func boostGasPriceForTransactOpts(
+	ctx context.Context,
+	opts *bind.TransactOpts,
+	client EthClient,
+	strategy GasStrategy,
+	percent uint64,
+	logger *slog.Logger,
+) error {
+	logger.Debug("gas price for tx that was not included", "gas_price", opts.GasPrice.String())
+	boosted, err := strategy.BoostGasPrice(ctx, client, opts.GasPrice, percent)
+	if err != nil {
+		return err
+	}
+	opts.GasPrice = boosted
+	logger.Info(
+		"boosting gas price for faster tx inclusion",
+		"percent", percent,
+		"boosted_gas_price", opts.GasPrice.String(),
+	)
+	return nil
+}
+
+// SupportsLondon reports whether the chain has activated EIP-1559, by checking
+// the latest header for a base fee.
+func SupportsLondon(ctx context.Context, client EthClient) (bool, error) {
+	header, err := client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to get latest header: %w", err)
+	}
+	return header.BaseFee != nil, nil
+}
+
+// FeeParams returns the gas tip cap and gas fee cap of opts. Both are the gas
+// price for legacy txs.
+func FeeParams(opts *bind.TransactOpts) (gasTip, gasFeeCap *big.Int) {
+	if opts.GasPrice != nil {
+		return opts.GasPrice, opts.GasPrice
+	}
+	return opts.GasTipCap, opts.GasFeeCap
+}
+
+func addPercentTo(value *big.Int, percent uint64) *big.Int {
+	increase := new(big.Int).Mul(value, new(big.Int).SetUint64(percent))
+	return increase.Add(value, increase.Div(increase, big.NewInt(100)))
 }
 
+// TxSubmitFunc signs and sends a tx with opts. If sending fails, it may return
+// the signed tx along with the error, so that a tx the node already knows can be
+// watched.
 type TxSubmitFunc func(
 	ctx context.Context,
 	opts *bind.TransactOpts,
@@ -127,36 +197,110 @@ type TxSubmitFunc func(
 	err error,
 )
 
+// WaitMinedWithRetry submits a tx and waits for it to be mined, boosting its fees
+// and resubmitting it each time it is not included within the wait of policy,
+// or is reorged out before reaching the policy's confirmations. Every version
+// submitted is watched, so whichever of them is mined is returned. If the nonce
+// is used by another tx instead, ErrNonceConsumed is returned.
+// Boosts that would exceed the policy's fee ceiling are handled according to the
+// ceiling's policy.
 func WaitMinedWithRetry(ctx context.Context, opts *bind.TransactOpts, submitTx TxSubmitFunc,
-	client EthClient, logger *slog.Logger) (*types.Receipt, error) {
+	client EthClient, policy RetryPolicy, logger *slog.Logger) (*types.Receipt, error) {
+
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	ceiling := policy.Ceiling
 
-	const maxRetries = 10
 	var err error
-	var tx *types.Transaction
+	var tx, pendingTx *types.Transaction
+	var sentTxs []*types.Transaction
+	atCeiling := false
 
-	for attempt := 0; attempt < maxRetries; attempt++ {
-		if attempt > 0 {
-			logger.Info("transaction not included within 60 seconds, boosting gas tip by 10%", "attempt", attempt)
-			if err := BoostTipForTransactOpts(ctx, opts, client, logger); err != nil {
+	gasTip, gasFeeCap := FeeParams(opts)
+	if err := ceiling.Check(gasTip, gasFeeCap, opts.GasLimit); err != nil {
+		return nil, err
+	}
+
+	for attempt := 0; attempt < policy.Attempts; attempt++ {
+		if attempt > 0 && !atCeiling {
+			logger.Info("transaction not included in time, boosting gas tip",
+				"attempt", attempt, "percent", policy.BoostPercent)
+			boosted := *opts
+			err := BoostTipForTransactOpts(ctx, &boosted, client, policy.strategy(), policy.BoostPercent, logger)
+			if err != nil {
 				return nil, fmt.Errorf("failed to boost gas tip for attempt %d: %w", attempt, err)
 			}
+			prevTip, prevFeeCap := FeeParams(opts)
+			boostedTip, boostedFeeCap := FeeParams(&boosted)
+			err = ceiling.Check(boostedTip, boostedFeeCap, boosted.GasLimit)
+			// Nodes only accept a replacement that raises both fee params by 10%.
+			if err == nil && (boostedFeeCap.Cmp(addPercentTo(prevFeeCap, minBoostPercent)) < 0 ||
+				boostedTip.Cmp(addPercentTo(prevTip, minBoostPercent)) < 0) {
+				err = fmt.Errorf("%w: fees capped too close to the pending tx to replace it", ErrFeeCeilingReached)
+			}
+			switch {
+			case err == nil:
+				opts.GasTipCap, opts.GasFeeCap, opts.GasPrice = boosted.GasTipCap, boosted.GasFeeCap, boosted.GasPrice
+			case pendingTx == nil:
+				return nil, err
+			case ceiling.policy() == CeilingPolicyWait:
+				logger.Warn("not boosting further, waiting for pending tx at fee ceiling",
+					"txHash", pendingTx.Hash().Hex(), "error", err)
+				atCeiling = true
+			default:
+				return nil, fmt.Errorf("%w, last pending tx %s", err, pendingTx.Hash().Hex())
+			}
 		}
 
-		tx, err = submitTx(ctx, opts)
-		if err != nil {
-			if strings.Contains(err.Error(), "replacement transaction underpriced") || strings.Contains(err.Error(), "already known") {
-				logger.Debug("tx submission failed", "attempt", attempt, "error", err)
+		if !atCeiling {
+			tx, err = submitTx(ctx, opts)
+			err = ClassifyError(err)
+			switch {
+			case err == nil:
+				pendingTx = tx
+				sentTxs = append(sentTxs, tx)
+			case errors.Is(err, ErrAlreadyKnown) && tx != nil:
+				// Sent before, e.g. through another endpoint, watch it rather than boost again.
+				logger.Debug("tx already known, watching it", "txHash", tx.Hash().Hex(), "attempt", attempt)
+				pendingTx = tx
+				if !slices.ContainsFunc(sentTxs, func(sent *types.Transaction) bool { return sent.Hash() == tx.Hash() }) {
+					sentTxs = append(sentTxs, tx)
+				}
+			case IsRetryable(err) && pendingTx != nil:
+				logger.Debug("tx submission failed, waiting for submitted txs", "attempt", attempt, "error", err)
+			case IsRetryable(err):
+				logger.Debug("tx submission failed, retrying after wait", "attempt", attempt, "error", err)
+				attemptCtx, cancel, _, err := policy.attemptContext(ctx, client, attempt, logger)
+				if err != nil {
+					return nil, err
+				}
+				<-attemptCtx.Done()
+				cancel()
+				if ctx.Err() != nil {
+					return nil, ctx.Err()
+				}
 				continue
+			case errors.Is(err, ErrNonceTooLow) && pendingTx != nil:
+				// A previous attempt, or the reorged tx, was mined before it could be replaced.
+				logger.Debug("nonce already used, waiting for submitted txs", "txs", len(sentTxs))
+			default:
+				return nil, fmt.Errorf("tx submission failed on attempt %d: %w", attempt, err)
 			}
-			return nil, fmt.Errorf("tx submission failed on attempt %d: %w", attempt, err)
 		}
 
-		timeoutCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
-		receiptChan := make(chan *types.Receipt)
-		errChan := make(chan error)
+		attemptCtx, cancel, wait, err := policy.attemptContext(ctx, client, attempt, logger)
+		if err != nil {
+			return nil, err
+		}
+		logger.Debug("waiting for tx to be mined",
+			"txHash", pendingTx.Hash().Hex(), "versions", len(sentTxs), "attempt", attempt, "wait", wait)
+		receiptChan := make(chan *types.Receipt, 1)
+		errChan := make(chan error, 1)
 
+		watched := slices.Clone(sentTxs)
 		go func() {
-			receipt, err := bind.WaitMined(timeoutCtx, client, tx)
+			receipt, err := WaitAnyMined(attemptCtx, client, watched, logger)
 			if err != nil {
 				errChan <- err
 				return
@@ -167,17 +311,35 @@ func WaitMinedWithRetry(ctx context.Context, opts *bind.TransactOpts, submitTx T
 		select {
 		case receipt := <-receiptChan:
 			cancel()
+			if receipt.TxHash != pendingTx.Hash() {
+				logger.Info("an earlier version of the tx was mined", "txHash", receipt.TxHash.Hex())
+			}
+			receipt, err = WaitConfirmed(ctx, client, receipt, policy.Confirmations, logger)
+			if errors.Is(err, ErrReorged) {
+				logger.Warn("tx reorged out before enough confirmations, resubmitting", "error", err)
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ceiling.RecordReceipt(receipt)
 			return receipt, nil
 		case err := <-errChan:
 			cancel()
-			return nil, err
-		case <-timeoutCtx.Done():
+			if attemptCtx.Err() == nil || ctx.Err() != nil {
+				return nil, err
+			}
+		case <-attemptCtx.Done():
 			cancel()
-			if attempt == maxRetries-1 {
-				return nil, fmt.Errorf("tx not included after %d attempts", maxRetries)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
 			}
-			// Continue with boosted tip
 		}
+		// Continue with boosted tip
+	}
+	if pendingTx != nil {
+		return nil, fmt.Errorf("tx not included after %d attempts, last pending tx %s",
+			policy.Attempts, pendingTx.Hash().Hex())
 	}
-	return nil, fmt.Errorf("unexpected error: control flow should not reach end of WaitMinedWithRetry")
+	return nil, fmt.Errorf("tx not submitted after %d attempts", policy.Attempts)
 }
@@ -2,14 +2,19 @@ package tx_test
 
 import (
 	"context"
+	"crypto/ecdsa"
 	"eigen-operator-cli/pkg/tx"
+	"errors"
 	"log/slog"
 	"math/big"
 	"testing"
+	"time"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"gotest.tools/assert"
 )
 
@@ -25,6 +30,7 @@ func NewMockEthClient(
 type MockEthClient struct {
 	suggestGasTipCap func() (*big.Int, error)
 	suggestGasPrice  func() (*big.Int, error)
+	feeHistory       *ethereum.FeeHistory
 }
 
 func (m *MockEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
@@ -43,6 +49,20 @@ func (m *MockEthClient) NonceAt(ctx context.Context, account common.Address, blo
 	return 0, nil
 }
 
+func (m *MockEthClient) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, nil
+}
+
+func (m *MockEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return nil, nil
+}
+
+func (m *MockEthClient) FeeHistory(
+	ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64,
+) (*ethereum.FeeHistory, error) {
+	return m.feeHistory, nil
+}
+
 func (m *MockEthClient) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
 	return m.suggestGasTipCap()
 }
@@ -56,6 +76,7 @@ func TestBoostTipForTransactOpts(t *testing.T) {
 	type gasParams struct {
 		GasTipCap *big.Int
 		GasFeeCap *big.Int
+		GasPrice  *big.Int
 	}
 
 	testCases := []struct {
@@ -143,6 +164,30 @@ func TestBoostTipForTransactOpts(t *testing.T) {
 				GasFeeCap: big.NewInt(331), // 1.1 * 300 + 1
 			},
 		},
+		{
+			name: "legacy boosted, suggestion increased",
+			suggestGasPrice: func() (*big.Int, error) {
+				return big.NewInt(1000), nil
+			},
+			gasParams: gasParams{
+				GasPrice: big.NewInt(900),
+			},
+			expectedGasParams: gasParams{
+				GasPrice: big.NewInt(1101), // 1.1 * 1000 + 1
+			},
+		},
+		{
+			name: "legacy boosted, suggestion decreased",
+			suggestGasPrice: func() (*big.Int, error) {
+				return big.NewInt(150), nil
+			},
+			gasParams: gasParams{
+				GasPrice: big.NewInt(300),
+			},
+			expectedGasParams: gasParams{
+				GasPrice: big.NewInt(331), // 1.1 * 300 + 1
+			},
+		},
 	}
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
@@ -150,8 +195,9 @@ func TestBoostTipForTransactOpts(t *testing.T) {
 			opts := &bind.TransactOpts{}
 			opts.GasFeeCap = tc.gasParams.GasFeeCap
 			opts.GasTipCap = tc.gasParams.GasTipCap
+			opts.GasPrice = tc.gasParams.GasPrice
 			errOutput := tx.BoostTipForTransactOpts(
-				context.Background(), opts, mockEthClient, slog.Default(),
+				context.Background(), opts, mockEthClient, tx.NodeGasStrategy{}, 10, slog.Default(),
 			)
 			outputParams := gasParams{
 				GasTipCap: opts.GasTipCap,
@@ -168,6 +214,296 @@ func TestBoostTipForTransactOpts(t *testing.T) {
 			if tc.expectedGasParams.GasTipCap != nil {
 				assert.Equal(t, tc.expectedGasParams.GasTipCap.Uint64(), outputParams.GasTipCap.Uint64())
 			}
+			if tc.expectedGasParams.GasPrice != nil {
+				assert.Equal(t, tc.expectedGasParams.GasPrice.Uint64(), opts.GasPrice.Uint64())
+				assert.Assert(t, opts.GasTipCap == nil && opts.GasFeeCap == nil)
+			}
+		})
+	}
+}
+
+func TestGasLimitWithMargin(t *testing.T) {
+	testCases := []struct {
+		name              string
+		estimate          uint64
+		multiplier        float64
+		maxGasLimit       uint64
+		expectedGasLimit  uint64
+		errExpectedOutput string
+	}{
+		{
+			name:             "no margin",
+			estimate:         100000,
+			multiplier:       1,
+			expectedGasLimit: 100000,
+		},
+		{
+			name:             "margin applied",
+			estimate:         100000,
+			multiplier:       1.2,
+			expectedGasLimit: 120000,
+		},
+		{
+			name:             "margin rounded up",
+			estimate:         33333,
+			multiplier:       1.5,
+			expectedGasLimit: 50000, // ceil(49999.5)
+		},
+		{
+			name:             "margin exact despite float rounding",
+			estimate:         21000,
+			multiplier:       1.1,
+			expectedGasLimit: 23100, // 21000 * 1.1 is 23100.000000000004 as a float
+		},
+		{
+			name:             "margin exact for fractional basis points",
+			estimate:         100000,
+			multiplier:       1.15,
+			expectedGasLimit: 115000,
+		},
+		{
+			name:             "margin capped",
+			estimate:         100000,
+			multiplier:       2,
+			maxGasLimit:      150000,
+			expectedGasLimit: 150000,
+		},
+		{
+			name:             "estimate equal to cap",
+			estimate:         150000,
+			multiplier:       1.2,
+			maxGasLimit:      150000,
+			expectedGasLimit: 150000,
+		},
+		{
+			name:              "error, estimate exceeds cap",
+			estimate:          200000,
+			multiplier:        1.2,
+			maxGasLimit:       150000,
+			errExpectedOutput: "estimated gas 200000 exceeds max gas limit 150000",
+		},
+		{
+			name:              "error, multiplier below one",
+			estimate:          100000,
+			multiplier:        0.9,
+			errExpectedOutput: "gas limit multiplier must be at least 1: 0.9",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			gasLimit, err := tx.GasLimitWithMargin(tc.estimate, tc.multiplier, tc.maxGasLimit)
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedGasLimit, gasLimit)
+		})
+	}
+}
+
+type headerMockEthClient struct {
+	*MockEthClient
+	header *types.Header
+}
+
+func (m *headerMockEthClient) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return m.header, nil
+}
+
+func TestSupportsLondon(t *testing.T) {
+	testCases := []struct {
+		name     string
+		header   *types.Header
+		expected bool
+	}{
+		{
+			name:     "london",
+			header:   &types.Header{BaseFee: big.NewInt(7)},
+			expected: true,
+		},
+		{
+			name:     "pre-london",
+			header:   &types.Header{},
+			expected: false,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			london, err := tx.SupportsLondon(context.Background(), &headerMockEthClient{header: tc.header})
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expected, london)
+		})
+	}
+}
+
+// sentTxRecorder signs and records the txs submitted by WaitMinedWithRetry,
+// failing submissions with sendErrs in order.
+type sentTxRecorder struct {
+	key      *ecdsa.PrivateKey
+	sendErrs []error
+	sent     []*types.Transaction
+	calls    []time.Time
+}
+
+func (r *sentTxRecorder) submit(ctx context.Context, opts *bind.TransactOpts) (*types.Transaction, error) {
+	r.calls = append(r.calls, time.Now())
+	signed, err := signedTestTx(r.key, opts.Nonce.Uint64(), opts.GasTipCap, opts.GasFeeCap)
+	if err != nil {
+		return nil, err
+	}
+	if len(r.sendErrs) > 0 {
+		err := r.sendErrs[0]
+		r.sendErrs = r.sendErrs[1:]
+		if err != nil {
+			return signed, err
+		}
+	}
+	r.sent = append(r.sent, signed)
+	return signed, nil
+}
+
+func signedTestTx(key *ecdsa.PrivateKey, nonce uint64, gasTipCap, gasFeeCap *big.Int) (*types.Transaction, error) {
+	to := common.HexToAddress("0x01")
+	return types.SignNewTx(key, types.LatestSignerForChainID(big.NewInt(1)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       21000,
+		To:        &to,
+	})
+}
+
+func TestWaitMinedWithRetrySubmissionErrors(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	minedIn := func(gasTipCap, gasFeeCap int64) map[common.Hash]*types.Receipt {
+		signed, err := signedTestTx(key, 5, big.NewInt(gasTipCap), big.NewInt(gasFeeCap))
+		assert.NilError(t, err)
+		return map[common.Hash]*types.Receipt{signed.Hash(): {TxHash: signed.Hash()}}
+	}
+	const wait = 200 * time.Millisecond
+
+	testCases := []struct {
+		name            string
+		sendErrs        []error
+		receipts        map[common.Hash]*types.Receipt
+		expectedCalls   int
+		expectedMinWait time.Duration
+		expectedSentTxs int
+	}{
+		{
+			name:          "already known tx is watched",
+			sendErrs:      []error{errors.New("already known")},
+			receipts:      minedIn(100, 1000),
+			expectedCalls: 1,
+		},
+		{
+			name:            "retryable error waits before boosting",
+			sendErrs:        []error{errors.New("transaction underpriced")},
+			receipts:        minedIn(111, 1101),
+			expectedCalls:   2,
+			expectedMinWait: wait,
+			expectedSentTxs: 1,
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			client := &watchMockEthClient{
+				MockEthClient: NewMockEthClient(
+					func() (*big.Int, error) { return big.NewInt(100), nil },
+					func() (*big.Int, error) { return big.NewInt(1000), nil },
+				),
+				nonce:    5,
+				receipts: tc.receipts,
+			}
+			opts := &bind.TransactOpts{Nonce: big.NewInt(5), GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000)}
+			recorder := &sentTxRecorder{key: key, sendErrs: tc.sendErrs}
+			policy := tx.RetryPolicy{Attempts: 3, Wait: wait, BoostPercent: 10}
+
+			receipt, err := tx.WaitMinedWithRetry(context.Background(), opts, recorder.submit, client, policy, slog.Default())
+			assert.NilError(t, err)
+			_, ok := tc.receipts[receipt.TxHash]
+			assert.Assert(t, ok, "unexpected tx mined: %s", receipt.TxHash.Hex())
+			assert.Equal(t, tc.expectedCalls, len(recorder.calls))
+			assert.Equal(t, tc.expectedSentTxs, len(recorder.sent))
+			if tc.expectedMinWait > 0 {
+				assert.Assert(t, recorder.calls[1].Sub(recorder.calls[0]) >= tc.expectedMinWait)
+			}
 		})
 	}
 }
+
+func TestWaitMinedWithRetryFeeCeiling(t *testing.T) {
+	testCases := []struct {
+		name              string
+		policy            tx.CeilingPolicy
+		errExpectedOutput string
+	}{
+		{
+			name:              "stop at ceiling",
+			policy:            tx.CeilingPolicyStop,
+			errExpectedOutput: "fee ceiling reached: gas fee cap 1212 exceeds max 1150, last pending tx",
+		},
+		{
+			name:              "wait at ceiling",
+			policy:            tx.CeilingPolicyWait,
+			errExpectedOutput: "tx not included after 3 attempts, last pending tx",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			key, err := crypto.GenerateKey()
+			assert.NilError(t, err)
+			client := &watchMockEthClient{
+				MockEthClient: NewMockEthClient(
+					func() (*big.Int, error) { return big.NewInt(100), nil },
+					func() (*big.Int, error) { return big.NewInt(1000), nil },
+				),
+				nonce: 5,
+			}
+			opts := &bind.TransactOpts{Nonce: big.NewInt(5), GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000)}
+			recorder := &sentTxRecorder{key: key}
+			policy := tx.RetryPolicy{
+				Attempts:     3,
+				Wait:         20 * time.Millisecond,
+				BoostPercent: 10,
+				Ceiling:      &tx.FeeCeiling{MaxGasFeeCap: big.NewInt(1150), Policy: tc.policy},
+			}
+
+			_, err = tx.WaitMinedWithRetry(context.Background(), opts, recorder.submit, client, policy, slog.Default())
+			assert.ErrorContains(t, err, tc.errExpectedOutput)
+			assert.Equal(t, tc.policy == tx.CeilingPolicyStop, errors.Is(err, tx.ErrFeeCeilingReached))
+			// The first boost fits under the ceiling, the second is never sent.
+			assert.Equal(t, 2, len(recorder.sent))
+			assert.Equal(t, "1101", recorder.sent[1].GasFeeCap().String())
+		})
+	}
+}
+
+func TestWaitMinedWithRetryFeesAboveCeiling(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	client := &watchMockEthClient{
+		MockEthClient: NewMockEthClient(
+			func() (*big.Int, error) { return big.NewInt(100), nil },
+			func() (*big.Int, error) { return big.NewInt(1000), nil },
+		),
+		nonce: 5,
+	}
+	opts := &bind.TransactOpts{Nonce: big.NewInt(5), GasTipCap: big.NewInt(100), GasFeeCap: big.NewInt(1000)}
+	recorder := &sentTxRecorder{key: key}
+	policy := tx.RetryPolicy{
+		Attempts:     3,
+		Wait:         20 * time.Millisecond,
+		BoostPercent: 10,
+		Ceiling:      &tx.FeeCeiling{MaxGasFeeCap: big.NewInt(900), Policy: tx.CeilingPolicyWait},
+	}
+
+	_, err = tx.WaitMinedWithRetry(context.Background(), opts, recorder.submit, client, policy, slog.Default())
+	assert.ErrorContains(t, err, "fee ceiling reached: gas fee cap 1000 exceeds max 900")
+	// Fees above the ceiling are refused rather than clamped to it.
+	assert.Equal(t, 0, len(recorder.sent))
+	assert.Equal(t, "1000", opts.GasFeeCap.String())
+}
@@ -0,0 +1,113 @@
+package tx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// ErrNonceConsumed is returned when the nonce of the watched txs was used by a tx
+// other than them, so none of them can be mined anymore.
+var ErrNonceConsumed = errors.New("nonce consumed by another tx")
+
+// receiptPollInterval is how often the receipts of watched txs are polled.
+const receiptPollInterval = time.Second
+
+// WaitAnyMined waits until one of txs, versions of a tx sent from the same account
+// with the same nonce, is mined and returns its receipt. Every version is watched,
+// so a lower fee one mined instead of its replacement is found too. If the account's
+// nonce moves past theirs without any of them being mined, ErrNonceConsumed is returned.
+func WaitAnyMined(
+	ctx context.Context,
+	client EthClient,
+	txs []*types.Transaction,
+	logger *slog.Logger,
+) (*types.Receipt, error) {
+	if len(txs) == 0 {
+		return nil, fmt.Errorf("no txs to wait for")
+	}
+	nonce := txs[0].Nonce()
+	for _, tx := range txs[1:] {
+		if tx.Nonce() != nonce {
+			return nil, fmt.Errorf("txs to wait for have different nonces: %d != %d", nonce, tx.Nonce())
+		}
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(txs[0].ChainId()), txs[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover tx sender: %w", err)
+	}
+
+	ticker := time.NewTicker(receiptPollInterval)
+	defer ticker.Stop()
+	for {
+		// The nonce is read before the receipts, so that a nonce moved past by one
+		// of txs always comes with its receipt.
+		accountNonce, nonceErr := client.NonceAt(ctx, from, nil)
+		receipt, err := anyReceipt(ctx, client, txs)
+		switch {
+		case receipt != nil:
+			return receipt, nil
+		case err != nil:
+			logger.Debug("receipt retrieval failed", "error", err)
+		case nonceErr != nil:
+			logger.Debug("nonce retrieval failed", "error", nonceErr)
+		case accountNonce > nonce:
+			return nil, fmt.Errorf("%w: nonce %d of %s is used, but none of txs %v was mined",
+				ErrNonceConsumed, nonce, from.Hex(), txHashes(txs))
+		default:
+			logger.Debug("txs not yet mined", "nonce", nonce, "txs", len(txs))
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// anyReceipt concurrently fetches the receipts of txs and returns the one found,
+// if any. At most one of txs can be mined, since they share a nonce.
+func anyReceipt(ctx context.Context, client EthClient, txs []*types.Transaction) (*types.Receipt, error) {
+	type result struct {
+		receipt *types.Receipt
+		err     error
+	}
+	results := make(chan result, len(txs))
+	for _, tx := range txs {
+		go func() {
+			receipt, err := client.TransactionReceipt(ctx, tx.Hash())
+			results <- result{receipt: receipt, err: err}
+		}()
+	}
+
+	var found *types.Receipt
+	var errs []error
+	for range txs {
+		r := <-results
+		switch {
+		case r.err == nil && r.receipt != nil:
+			found = r.receipt
+		case r.err != nil && !errors.Is(r.err, ethereum.NotFound):
+			errs = append(errs, r.err)
+		}
+	}
+	if found != nil {
+		return found, nil
+	}
+	return nil, errors.Join(errs...)
+}
+
+func txHashes(txs []*types.Transaction) []common.Hash {
+	hashes := make([]common.Hash, len(txs))
+	for i, tx := range txs {
+		hashes[i] = tx.Hash()
+	}
+	return hashes
+}
@@ -0,0 +1,115 @@
+package tx_test
+
+import (
+	"context"
+	"eigen-operator-cli/pkg/tx"
+	"errors"
+	"log/slog"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"gotest.tools/assert"
+)
+
+type watchMockEthClient struct {
+	*MockEthClient
+	nonce    uint64
+	receipts map[common.Hash]*types.Receipt
+}
+
+func (m *watchMockEthClient) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	if receipt, ok := m.receipts[txHash]; ok {
+		return receipt, nil
+	}
+	return nil, ethereum.NotFound
+}
+
+func (m *watchMockEthClient) NonceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (uint64, error) {
+	return m.nonce, nil
+}
+
+func TestWaitAnyMined(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NilError(t, err)
+	signer := types.LatestSignerForChainID(big.NewInt(1))
+	signedTx := func(nonce uint64, gasTipCap int64) *types.Transaction {
+		to := common.HexToAddress("0x01")
+		signed, err := types.SignNewTx(key, signer, &types.DynamicFeeTx{
+			ChainID:   big.NewInt(1),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(gasTipCap),
+			GasFeeCap: big.NewInt(100),
+			Gas:       21000,
+			To:        &to,
+		})
+		assert.NilError(t, err)
+		return signed
+	}
+	original := signedTx(5, 1)
+	boosted := signedTx(5, 2)
+	minedIn := func(signed *types.Transaction) map[common.Hash]*types.Receipt {
+		return map[common.Hash]*types.Receipt{signed.Hash(): {TxHash: signed.Hash()}}
+	}
+
+	testCases := []struct {
+		name              string
+		txs               []*types.Transaction
+		client            *watchMockEthClient
+		expectedTxHash    common.Hash
+		expectedErr       error
+		errExpectedOutput string
+	}{
+		{
+			name:           "newest mined",
+			txs:            []*types.Transaction{original, boosted},
+			client:         &watchMockEthClient{nonce: 6, receipts: minedIn(boosted)},
+			expectedTxHash: boosted.Hash(),
+		},
+		{
+			name:           "earlier version mined",
+			txs:            []*types.Transaction{original, boosted},
+			client:         &watchMockEthClient{nonce: 6, receipts: minedIn(original)},
+			expectedTxHash: original.Hash(),
+		},
+		{
+			name:        "nonce consumed by another tx",
+			txs:         []*types.Transaction{original, boosted},
+			client:      &watchMockEthClient{nonce: 6},
+			expectedErr: tx.ErrNonceConsumed,
+		},
+		{
+			name:        "not mined yet",
+			txs:         []*types.Transaction{original},
+			client:      &watchMockEthClient{nonce: 5},
+			expectedErr: context.DeadlineExceeded,
+		},
+		{
+			name:              "error, different nonces",
+			txs:               []*types.Transaction{original, signedTx(6, 1)},
+			client:            &watchMockEthClient{},
+			errExpectedOutput: "txs to wait for have different nonces: 5 != 6",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			receipt, err := tx.WaitAnyMined(ctx, tc.client, tc.txs, slog.Default())
+			if tc.errExpectedOutput != "" {
+				assert.Error(t, err, tc.errExpectedOutput)
+				return
+			}
+			if tc.expectedErr != nil {
+				assert.Assert(t, errors.Is(err, tc.expectedErr), "unexpected error: %v", err)
+				return
+			}
+			assert.NilError(t, err)
+			assert.Equal(t, tc.expectedTxHash, receipt.TxHash)
+		})
+	}
+}